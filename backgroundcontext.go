@@ -0,0 +1,97 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkBackgroundContext reports span starts passed context.Background() or
+// context.TODO() while a context is in scope. The span becomes the root of a new
+// trace, disconnected from the trace of the context in scope.
+func checkBackgroundContext(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+
+	arg, ok := call.Args[0].(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	name := getEmptyContextFunc(pass.TypesInfo, arg)
+	if name == "" {
+		return
+	}
+
+	ctx := getContextInScope(pass, call)
+	if ctx == nil {
+		return
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:            arg.Pos(),
+		End:            arg.End(),
+		Message:        fmt.Sprintf("span is started with context.%s() while %s is in scope, the span will not be a child of %s", name, ctx.Name(), ctx.Name()),
+		SuggestedFixes: getBackgroundContextFixes(arg, ctx),
+	})
+}
+
+// getEmptyContextFunc returns "Background" or "TODO" if call is context.Background()
+// or context.TODO(), and "" otherwise.
+func getEmptyContextFunc(info *types.Info, call *ast.CallExpr) string {
+	var id *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	case *ast.Ident:
+		id = fun
+	default:
+		return ""
+	}
+
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "context" {
+		return ""
+	}
+
+	switch fn.Name() {
+	case "Background", "TODO":
+		return fn.Name()
+	}
+
+	return ""
+}
+
+// getContextInScope returns the innermost context variable that is in scope at call.
+// If a scope declares several contexts, the last declared one is returned.
+func getContextInScope(pass *analysis.Pass, call *ast.CallExpr) *types.Var {
+	pkgScope := pass.Pkg.Scope()
+	innermost := pkgScope.Innermost(call.Pos())
+	for scope := innermost; scope != nil && scope != pkgScope; scope = scope.Parent() {
+		var ctx *types.Var
+		for _, name := range scope.Names() {
+			v, ok := scope.Lookup(name).(*types.Var)
+			if !ok || name == "_" || !isContextType(v.Type()) {
+				continue
+			}
+
+			// The variable must be declared before the call, and not shadowed.
+			if _, obj := innermost.LookupParent(name, call.Pos()); obj != v {
+				continue
+			}
+
+			if ctx == nil || v.Pos() > ctx.Pos() {
+				ctx = v
+			}
+		}
+
+		if ctx != nil {
+			return ctx
+		}
+	}
+
+	return nil
+}
@@ -2,8 +2,11 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"log"
+	"os"
 	"strings"
 
 	"golang.org/x/tools/go/analysis/singlechecker"
@@ -28,11 +31,26 @@ func main() {
 	extraStartSpanSignatures := ""
 	flag.StringVar(&extraStartSpanSignatures, "extra-start-span-signatures", "", "comma-separated list of regex:telemetry-type for function signatures that indicate the start of a span")
 
+	spanNamePattern := ""
+	flag.StringVar(&spanNamePattern, "span-name-pattern", "", "regex that span names must match, if the span-name check is enabled")
+
+	tracerNameImportPath := false
+	flag.BoolVar(&tracerNameImportPath, "tracer-name-import-path", false, "require tracer names to be the package import path, if the tracer-usage check is enabled")
+
+	spanLibrariesFile := ""
+	flag.StringVar(&spanLibrariesFile, "span-libraries-file", "", "path to a JSON file with a list of span libraries to check, in addition to the built-in ones")
+
 	flag.Parse()
 
 	cfg := spancheck.NewDefaultConfig()
 	cfg.EnabledChecks = strings.Split(checkStrings, ",")
 	cfg.IgnoreChecksSignaturesSlice = strings.Split(ignoreCheckSignatures, ",")
+	cfg.SpanNamePattern = spanNamePattern
+	cfg.TracerNameImportPath = tracerNameImportPath
+
+	if spanLibrariesFile != "" {
+		cfg.SpanLibraries = append(cfg.SpanLibraries, readSpanLibraries(spanLibrariesFile)...)
+	}
 
 	if extraStartSpanSignatures != "" {
 		cfg.StartSpanMatchersSlice = append(cfg.StartSpanMatchersSlice, strings.Split(extraStartSpanSignatures, ",")...)
@@ -40,3 +58,18 @@ func main() {
 
 	singlechecker.Main(spancheck.NewAnalyzerWithConfig(cfg))
 }
+
+// readSpanLibraries reads a JSON list of span libraries from the file at path.
+func readSpanLibraries(path string) []spancheck.SpanLibrary {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("failed to read span libraries: %v", err)
+	}
+
+	var libs []spancheck.SpanLibrary
+	if err := json.Unmarshal(data, &libs); err != nil {
+		log.Fatalf("failed to parse span libraries from %s: %v", path, err)
+	}
+
+	return libs
+}
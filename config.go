@@ -3,6 +3,7 @@ package spancheck
 import (
 	"flag"
 	"fmt"
+	"go/types"
 	"log"
 	"regexp"
 	"strings"
@@ -20,20 +21,55 @@ const (
 
 	// RecordErrorCheck if enabled, checks that span.RecordError(err) is called when returning an error.
 	RecordErrorCheck
-)
 
-var (
-	startSpanSignatureCols     = 2
-	defaultStartSpanSignatures = []string{
-		// https://github.com/open-telemetry/opentelemetry-go/blob/98b32a6c3a87fbee5d34c063b9096f416b250897/trace/trace.go#L523
-		`\(go.opentelemetry.io/otel/trace.Tracer\).Start:opentelemetry`,
-		// https://pkg.go.dev/go.opencensus.io/trace#StartSpan
-		`go.opencensus.io/trace.StartSpan:opencensus`,
-		// https://github.com/census-instrumentation/opencensus-go/blob/v0.24.0/trace/trace_api.go#L66
-		`go.opencensus.io/trace.StartSpanWithRemoteParent:opencensus`,
-	}
+	// UseAfterEndCheck if enabled, checks that no methods are called on a span after span.End().
+	UseAfterEndCheck
+
+	// DoubleEndCheck if enabled, checks that span.End() is not called more than once on any path.
+	DoubleEndCheck
+
+	// ContextPropagationCheck if enabled, checks that the context returned by a span start is used
+	// in place of the context the span was started from.
+	ContextPropagationCheck
+
+	// BackgroundContextCheck if enabled, checks that spans are not started with
+	// context.Background() or context.TODO() while a context is in scope.
+	BackgroundContextCheck
+
+	// StatusConsistencyCheck if enabled, checks that no error status is set on a span
+	// before returning without an error.
+	StatusConsistencyCheck
+
+	// RecordErrorMatchCheck if enabled, checks that the error recorded with span.RecordError(err)
+	// is the error returned, or is wrapped by it.
+	RecordErrorMatchCheck
+
+	// LoopDeferEndCheck if enabled, checks that spans started in a loop are ended before
+	// the next iteration, rather than deferred.
+	LoopDeferEndCheck
+
+	// GoroutineEscapeCheck if enabled, checks that spans are not used by goroutines
+	// while the function that started them defers span.End().
+	GoroutineEscapeCheck
+
+	// SpanNestingCheck if enabled, checks that child spans are ended before their parent.
+	SpanNestingCheck
+
+	// ForeignSpanEndCheck if enabled, checks that End is not called on spans taken from
+	// a context, like with trace.SpanFromContext(ctx).
+	ForeignSpanEndCheck
+
+	// SpanNameCheck if enabled, checks that span names are constants, matching
+	// SpanNamePattern if it is set.
+	SpanNameCheck
+
+	// TracerUsageCheck if enabled, checks that tracers that start spans are created once,
+	// in package-level vars, with non-empty names.
+	TracerUsageCheck
 )
 
+const startSpanSignatureCols = 2
+
 func (c Check) String() string {
 	switch c {
 	case EndCheck:
@@ -42,6 +78,30 @@ func (c Check) String() string {
 		return "set-status"
 	case RecordErrorCheck:
 		return "record-error"
+	case UseAfterEndCheck:
+		return "use-after-end"
+	case DoubleEndCheck:
+		return "double-end"
+	case ContextPropagationCheck:
+		return "context-propagation"
+	case BackgroundContextCheck:
+		return "background-context"
+	case StatusConsistencyCheck:
+		return "status-consistency"
+	case RecordErrorMatchCheck:
+		return "record-error-match"
+	case LoopDeferEndCheck:
+		return "loop-defer-end"
+	case GoroutineEscapeCheck:
+		return "goroutine-escape"
+	case SpanNestingCheck:
+		return "span-nesting"
+	case ForeignSpanEndCheck:
+		return "foreign-span-end"
+	case SpanNameCheck:
+		return "span-name"
+	case TracerUsageCheck:
+		return "tracer-usage"
 	default:
 		return ""
 	}
@@ -49,14 +109,26 @@ func (c Check) String() string {
 
 // Checks is a list of all checks by name.
 var Checks = map[string]Check{
-	EndCheck.String():         EndCheck,
-	SetStatusCheck.String():   SetStatusCheck,
-	RecordErrorCheck.String(): RecordErrorCheck,
+	EndCheck.String():                EndCheck,
+	SetStatusCheck.String():          SetStatusCheck,
+	RecordErrorCheck.String():        RecordErrorCheck,
+	UseAfterEndCheck.String():        UseAfterEndCheck,
+	DoubleEndCheck.String():          DoubleEndCheck,
+	ContextPropagationCheck.String(): ContextPropagationCheck,
+	BackgroundContextCheck.String():  BackgroundContextCheck,
+	StatusConsistencyCheck.String():  StatusConsistencyCheck,
+	RecordErrorMatchCheck.String():   RecordErrorMatchCheck,
+	LoopDeferEndCheck.String():       LoopDeferEndCheck,
+	GoroutineEscapeCheck.String():    GoroutineEscapeCheck,
+	SpanNestingCheck.String():        SpanNestingCheck,
+	ForeignSpanEndCheck.String():     ForeignSpanEndCheck,
+	SpanNameCheck.String():           SpanNameCheck,
+	TracerUsageCheck.String():        TracerUsageCheck,
 }
 
 type spanStartMatcher struct {
 	signature *regexp.Regexp
-	spanType  spanType
+	lib       *SpanLibrary
 }
 
 // Config is a configuration for the spancheck analyzer.
@@ -70,25 +142,58 @@ type Config struct {
 	// the IgnoreSetStatusCheckSignatures regex.
 	IgnoreChecksSignaturesSlice []string
 
+	// StartSpanMatchersSlice is a slice of regex:telemetry-type strings for functions, other than
+	// those of SpanLibraries, that start spans. The telemetry type is the name of a span library.
 	StartSpanMatchersSlice []string
 
-	endCheckEnabled    bool
-	setStatusEnabled   bool
-	recordErrorEnabled bool
+	// SpanLibraries are the libraries whose spans are checked.
+	SpanLibraries []SpanLibrary
+
+	// SpanNamePattern is a regex that span names must match, if the span-name check is enabled.
+	SpanNamePattern string
+
+	// TracerNameImportPath requires tracer names to be the import path of the package,
+	// if the tracer-usage check is enabled.
+	TracerNameImportPath bool
+
+	endCheckEnabled           bool
+	setStatusEnabled          bool
+	recordErrorEnabled        bool
+	useAfterEndEnabled        bool
+	doubleEndEnabled          bool
+	contextPropagationEnabled bool
+	backgroundContextEnabled  bool
+	statusConsistencyEnabled  bool
+	recordErrorMatchEnabled   bool
+	loopDeferEndEnabled       bool
+	goroutineEscapeEnabled    bool
+	spanNestingEnabled        bool
+	foreignSpanEndEnabled     bool
+	spanNameEnabled           bool
+	tracerUsageEnabled        bool
 
 	// ignoreChecksSignatures is a regex that, if matched, disables the
 	// SetStatus and RecordError checks on error.
 	ignoreChecksSignatures *regexp.Regexp
 
+	// spanLibraries are the valid SpanLibraries.
+	spanLibraries []*SpanLibrary
+
+	// spanPackages are the import paths of the packages of the span libraries' span types.
+	spanPackages map[string]bool
+
 	startSpanMatchers            []spanStartMatcher
 	startSpanMatchersCustomRegex *regexp.Regexp
+
+	// spanNameRegex is the compiled SpanNamePattern.
+	spanNameRegex *regexp.Regexp
 }
 
 // NewDefaultConfig returns a new Config with default values.
 func NewDefaultConfig() *Config {
 	return &Config{
-		EnabledChecks:          []string{EndCheck.String()},
-		StartSpanMatchersSlice: defaultStartSpanSignatures,
+		EnabledChecks: []string{EndCheck.String()},
+		SpanLibraries: DefaultSpanLibraries(),
 	}
 }
 
@@ -100,12 +205,26 @@ func (c *Config) finalize() {
 	c.endCheckEnabled = contains(checks, EndCheck)
 	c.setStatusEnabled = contains(checks, SetStatusCheck)
 	c.recordErrorEnabled = contains(checks, RecordErrorCheck)
+	c.useAfterEndEnabled = contains(checks, UseAfterEndCheck)
+	c.doubleEndEnabled = contains(checks, DoubleEndCheck)
+	c.contextPropagationEnabled = contains(checks, ContextPropagationCheck)
+	c.backgroundContextEnabled = contains(checks, BackgroundContextCheck)
+	c.statusConsistencyEnabled = contains(checks, StatusConsistencyCheck)
+	c.recordErrorMatchEnabled = contains(checks, RecordErrorMatchCheck)
+	c.loopDeferEndEnabled = contains(checks, LoopDeferEndCheck)
+	c.goroutineEscapeEnabled = contains(checks, GoroutineEscapeCheck)
+	c.spanNestingEnabled = contains(checks, SpanNestingCheck)
+	c.foreignSpanEndEnabled = contains(checks, ForeignSpanEndCheck)
+	c.spanNameEnabled = contains(checks, SpanNameCheck)
+	c.tracerUsageEnabled = contains(checks, TracerUsageCheck)
 }
 
 // parseSignatures sets the Ignore*CheckSignatures regex from the string slices.
 func (c *Config) parseSignatures() {
 	c.parseIgnoreSignatures()
+	c.parseSpanLibraries()
 	c.parseStartSpanSignatures()
+	c.parseSpanNamePattern()
 }
 
 func (c *Config) parseIgnoreSignatures() {
@@ -118,13 +237,77 @@ func (c *Config) parseIgnoreSignatures() {
 	}
 }
 
+func (c *Config) parseSpanLibraries() {
+	if c.spanLibraries != nil {
+		return
+	}
+
+	c.spanLibraries = []*SpanLibrary{}
+	c.spanPackages = make(map[string]bool)
+	for _, lib := range c.SpanLibraries {
+		if !lib.finalize() {
+			continue
+		}
+
+		c.spanLibraries = append(c.spanLibraries, &lib)
+		for _, path := range lib.getSpanPackages() {
+			c.spanPackages[path] = true
+		}
+	}
+}
+
+// getSpanLibrary returns the span library with the name, or nil if there is none.
+func (c *Config) getSpanLibrary(name string) *SpanLibrary {
+	for _, lib := range c.spanLibraries {
+		if lib.Name == name {
+			return lib
+		}
+	}
+
+	return nil
+}
+
+// getParamSpanLibrary returns the span library with spans of type t, or nil if there is none.
+func (c *Config) getParamSpanLibrary(t types.Type) *SpanLibrary {
+	for _, lib := range c.spanLibraries {
+		if lib.isSpanType(t) {
+			return lib
+		}
+	}
+
+	return nil
+}
+
+// getForeignSpanLibrary returns the span library whose spans the function fn returns
+// without starting them, like SpanFromContext, or nil if there is none.
+func (c *Config) getForeignSpanLibrary(fn types.Object) *SpanLibrary {
+	for _, lib := range c.spanLibraries {
+		if lib.isForeignSpanSource(fn) {
+			return lib
+		}
+	}
+
+	return nil
+}
+
 func (c *Config) parseStartSpanSignatures() {
 	if c.startSpanMatchers != nil {
 		return
 	}
 
 	customMatchers := []string{}
-	for i, sig := range c.StartSpanMatchersSlice {
+	for _, lib := range c.spanLibraries {
+		for _, sig := range lib.StartSpanSignatures {
+			if regex := compileSignature("start span", sig); regex != nil {
+				c.startSpanMatchers = append(c.startSpanMatchers, spanStartMatcher{
+					signature: regex,
+					lib:       lib,
+				})
+			}
+		}
+	}
+
+	for _, sig := range c.StartSpanMatchersSlice {
 		parts := strings.Split(sig, ":")
 
 		// Make sure we have both a signature and a telemetry type
@@ -135,17 +318,11 @@ func (c *Config) parseStartSpanSignatures() {
 		}
 
 		sig, sigType := parts[0], parts[1]
-		if len(sig) < 1 {
-			log.Default().Print("[WARN] invalid start span signature, empty pattern")
-
-			continue
-		}
-
-		spanType, ok := SpanTypes[sigType]
-		if !ok {
-			validSpanTypes := make([]string, 0, len(SpanTypes))
-			for k := range SpanTypes {
-				validSpanTypes = append(validSpanTypes, k)
+		lib := c.getSpanLibrary(sigType)
+		if lib == nil {
+			validSpanTypes := make([]string, 0, len(c.spanLibraries))
+			for _, lib := range c.spanLibraries {
+				validSpanTypes = append(validSpanTypes, lib.Name)
 			}
 
 			log.Default().
@@ -154,26 +331,55 @@ func (c *Config) parseStartSpanSignatures() {
 			continue
 		}
 
-		regex, err := regexp.Compile(sig)
-		if err != nil {
-			log.Default().Printf("[WARN] failed to compile regex from signature %s: %v\n", sig, err)
-
+		regex := compileSignature("start span", sig)
+		if regex == nil {
 			continue
 		}
 
 		c.startSpanMatchers = append(c.startSpanMatchers, spanStartMatcher{
 			signature: regex,
-			spanType:  spanType,
+			lib:       lib,
 		})
-
-		if i >= len(defaultStartSpanSignatures) {
-			customMatchers = append(customMatchers, sig)
-		}
+		customMatchers = append(customMatchers, sig)
 	}
 
 	c.startSpanMatchersCustomRegex = createRegex(customMatchers)
 }
 
+// compileSignature returns the regex of a signature of the kind, like "start span", or nil
+// if it is invalid.
+func compileSignature(kind, sig string) *regexp.Regexp {
+	if len(sig) < 1 {
+		log.Default().Printf("[WARN] invalid %s signature, empty pattern\n", kind)
+
+		return nil
+	}
+
+	regex, err := regexp.Compile(sig)
+	if err != nil {
+		log.Default().Printf("[WARN] failed to compile regex from signature %s: %v\n", sig, err)
+
+		return nil
+	}
+
+	return regex
+}
+
+func (c *Config) parseSpanNamePattern() {
+	if c.spanNameRegex != nil || c.SpanNamePattern == "" {
+		return
+	}
+
+	regex, err := regexp.Compile(c.SpanNamePattern)
+	if err != nil {
+		log.Default().Printf("[WARN] failed to compile span name pattern %s: %v\n", c.SpanNamePattern, err)
+
+		return
+	}
+
+	c.spanNameRegex = regex
+}
+
 func parseChecks(checksSlice []string) []Check {
 	if len(checksSlice) == 0 {
 		return nil
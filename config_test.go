@@ -26,6 +26,9 @@ func Test_parseChecks(t *testing.T) {
 		"end,record-error,set-status": {
 			checks: []Check{EndCheck, RecordErrorCheck, SetStatusCheck},
 		},
+		"end,use-after-end": {
+			checks: []Check{EndCheck, UseAfterEndCheck},
+		},
 	} {
 		flag, tc := flag, tc
 		t.Run(flag, func(t *testing.T) {
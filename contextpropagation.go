@@ -0,0 +1,126 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkContextPropagation reports spans whose derived context is discarded, and calls
+// passing the context the span was started from while the span is in progress.
+// Spans started from those calls are not children of the span.
+func checkContextPropagation(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	start, ok := sv.start.(*ssa.Call)
+	if !ok {
+		return
+	}
+
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.EndMethod,
+	}
+
+	// The span is in progress until it is started anew or ended.
+	stop := func(instr ssa.Instruction) bool {
+		call, ok := instr.(*ssa.Call)
+		return instr == sv.start || ok && s.endsSpan(call)
+	}
+
+	if isContextDiscarded(pass.TypesInfo, sv.stmt, sv.call) {
+		if s.findCall(sv.start, stop, func(call ssa.CallInstruction) bool {
+			return len(getContextArgs(call.Common())) > 0
+		}) != nil {
+			pass.ReportRangef(sv.stmt, "context returned with %s is discarded, later calls will not be children of %s", sv.vr.Name(), sv.vr.Name())
+		}
+	}
+
+	// Find the context the span was started from.
+	ctxArgs := getContextArgs(start.Common())
+	if len(ctxArgs) == 0 {
+		return
+	}
+	parents := newValueAliases(fn, ctxArgs[0])
+
+	parentName := "context"
+	for _, arg := range sv.call.Args {
+		if isContextType(pass.TypesInfo.TypeOf(arg)) {
+			parentName = types.ExprString(arg)
+			break
+		}
+	}
+
+	calls := info.getCallExprs(fn)
+	s.findCall(sv.start, stop, func(call ssa.CallInstruction) bool {
+		for _, arg := range getContextArgs(call.Common()) {
+			if !parents.has(arg) {
+				continue
+			}
+
+			if expr, ok := calls[call.Common().Pos()]; ok {
+				pass.ReportRangef(expr, "%s is passed while %s is in progress, use the context returned with %s", parentName, sv.vr.Name(), sv.vr.Name())
+			}
+			break
+		}
+
+		return false // find all calls
+	})
+}
+
+// isContextDiscarded reports whether the context returned by the span start call
+// is assigned to the blank identifier in stmt.
+func isContextDiscarded(info *types.Info, stmt ast.Node, call *ast.CallExpr) bool {
+	tuple, ok := info.TypeOf(call).(*types.Tuple)
+	if !ok {
+		return false
+	}
+
+	i := 0
+	for ; i < tuple.Len(); i++ {
+		if isContextType(tuple.At(i).Type()) {
+			break
+		}
+	}
+
+	var lhs []ast.Expr
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		lhs = stmt.Lhs
+	case *ast.ValueSpec:
+		for _, name := range stmt.Names {
+			lhs = append(lhs, name)
+		}
+	}
+	if i >= len(lhs) || len(lhs) != tuple.Len() {
+		return false
+	}
+
+	id, ok := lhs[i].(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+// getContextArgs returns the arguments of call that are contexts.
+func getContextArgs(call *ssa.CallCommon) []ssa.Value {
+	var ctxs []ssa.Value
+	for _, arg := range call.Args {
+		if isContextType(arg.Type()) {
+			ctxs = append(ctxs, arg)
+		}
+	}
+
+	return ctxs
+}
+
+// isContextType reports whether t is context.Context.
+func isContextType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
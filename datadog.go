@@ -0,0 +1,74 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	datadogTracerPath = "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	datadogExtPath    = "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	datadogExtName    = "ext"
+	datadogErrorTag   = "error" // ext.Error
+	datadogWithError  = "WithError"
+
+	selNameFinish = "Finish"
+	selNameSetTag = "SetTag"
+)
+
+// datadogCallsMethod reports whether call stands for a call to selName on a Datadog span.
+// The SetTag calls that count mark the span as failed: span.SetTag(ext.Error, err), or
+// span.Finish(tracer.WithError(err)). Tags and finish options that are not known are
+// assumed to. Only calls on the span are known.
+func datadogCallsMethod(info *types.Info, selName, method string, call *ast.CallExpr) (calls, known bool) {
+	if method == "" {
+		return false, false
+	}
+
+	if selName != selNameSetTag {
+		return method == selName, true
+	}
+
+	switch method {
+	case selNameSetTag:
+		if len(call.Args) == 0 {
+			return false, true
+		}
+
+		key := info.Types[call.Args[0]].Value
+		return key == nil || key.Kind() != constant.String || constant.StringVal(key) == datadogErrorTag, true
+	case selNameFinish:
+		// span.Finish(opts...)
+		if call.Ellipsis.IsValid() {
+			return true, true
+		}
+
+		for _, arg := range call.Args {
+			opt, ok := ast.Unparen(arg).(*ast.CallExpr)
+			if !ok {
+				return true, true
+			}
+
+			if getFuncName(info, opt) == datadogTracerPath+"."+datadogWithError {
+				return true, true
+			}
+		}
+	}
+
+	return false, true
+}
+
+// getDatadogSetStatusCall returns span.SetTag(ext.Error, err), importing the ext package if needed.
+func getDatadogSetStatusCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, datadogExtPath, datadogExtName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.%s(%s.Error, %s)", span, selNameSetTag, name, errExpr), edits, true
+}
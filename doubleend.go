@@ -0,0 +1,78 @@
+package spancheck
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkDoubleEnd reports calls that end the span after it may already have been ended,
+// either explicitly or by a deferred call. Ending a span twice exports it twice with
+// some libraries, like OpenCensus.
+func checkDoubleEnd(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.EndMethod,
+	}
+
+	calls := info.getCallExprs(fn)
+	reported := make(map[ssa.CallInstruction]bool)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			// Deferred calls run at return, so the order of the calls does not matter.
+			end, ok := instr.(ssa.CallInstruction)
+			if !ok || !s.endsSpan(end) {
+				continue
+			}
+
+			again := s.findCall(end, s.isStartOrReassign(sv.start), s.endsSpan)
+			if again == nil || reported[again] {
+				continue
+			}
+			reported[again] = true
+
+			msg := fmt.Sprintf("%s.%s is called more than once on some paths", sv.vr.Name(), s.selName)
+			if call, ok := calls[again.Common().Pos()]; ok {
+				pass.ReportRangef(call, "%s", msg)
+			} else {
+				pass.Reportf(again.Pos(), "%s", msg)
+			}
+		}
+	}
+}
+
+// endsSpan reports whether call ends the span: it calls End on the span, passes
+// the span to a function that ends it, or is a function literal that ends it on all paths.
+func (s *callSearch) endsSpan(call ssa.CallInstruction) bool {
+	common := call.Common()
+	if s.callsMethod(common, s.selName) || callsSpanParam(s.pass, common, s.aliases, s.selName) {
+		return true
+	}
+
+	mc, ok := common.Value.(*ssa.MakeClosure)
+	if !ok {
+		return false
+	}
+
+	fn, aliases := s.aliases.forClosure(mc)
+	if fn == nil {
+		return false
+	}
+
+	closure := *s
+	closure.aliases = aliases
+
+	// The function literal must end the span somewhere, and on all of its paths.
+	uses := false
+	for _, b := range fn.Blocks {
+		if closure.usesCall(b.Instrs, 1) {
+			uses = true
+			break
+		}
+	}
+
+	return uses && closure.findMissingCall(fn, nil, 1, func(*ssa.Return) bool { return true }) == nil
+}
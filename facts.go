@@ -0,0 +1,275 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// spanStartFact is exported for functions that return a span obtained from a
+// span start. For example:
+//
+//	func StartTrace(ctx context.Context) (context.Context, trace.Span) {
+//		return otel.Tracer("app").Start(ctx, "span")
+//	}
+//
+// Calls to such functions are treated as span starts.
+type spanStartFact struct {
+	// Library is the name of the span's library.
+	Library string
+}
+
+func (*spanStartFact) AFact() {}
+
+func (f *spanStartFact) String() string {
+	return fmt.Sprintf("spanStart(%s)", f.Library)
+}
+
+// spanParamFact is exported for functions that call End, SetStatus, or RecordError
+// on a span parameter on all paths. For example:
+//
+//	func Record(span trace.Span, err error) error {
+//		span.SetStatus(codes.Error, err.Error())
+//		span.RecordError(err)
+//		return err
+//	}
+//
+// Callers passing their span to such functions satisfy the corresponding checks.
+// SetStatus calls only count if they set an error status.
+type spanParamFact struct {
+	// Calls maps the index of each span parameter to the selectors called on it.
+	Calls map[int][]string
+}
+
+func (*spanParamFact) AFact() {}
+
+func (f *spanParamFact) String() string {
+	params := make([]int, 0, len(f.Calls))
+	for i := range f.Calls {
+		params = append(params, i)
+	}
+	sort.Ints(params)
+
+	calls := make([]string, 0, len(params))
+	for _, i := range params {
+		calls = append(calls, fmt.Sprintf("%d: %s", i, strings.Join(f.Calls[i], ", ")))
+	}
+
+	return fmt.Sprintf("spanParam(%s)", strings.Join(calls, "; "))
+}
+
+// exportSpanStartFacts exports a spanStartFact for each function in the package
+// that returns a span from a span start.
+//
+// Functions in the package may wrap each other, so facts are recomputed until
+// none change.
+func exportSpanStartFacts(pass *analysis.Pass, info *ssaInfo, config *Config) {
+	decls := getFuncDecls(pass)
+
+	for changed := true; changed; {
+		changed = false
+		for _, decl := range decls {
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok || info.funcs[decl] == nil || pass.ImportObjectFact(fn, new(spanStartFact)) {
+				continue
+			}
+
+			if lib, ok := getReturnedSpanLibrary(pass, info.funcs[decl], config); ok {
+				pass.ExportObjectFact(fn, &spanStartFact{Library: lib.Name})
+				changed = true
+			}
+		}
+	}
+}
+
+// getReturnedSpanLibrary returns the library of the span the function returns from
+// a span start, if it returns one.
+func getReturnedSpanLibrary(pass *analysis.Pass, fn *ssa.Function, config *Config) (*SpanLibrary, bool) {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+
+			lib, isStart := isSpanStartCall(pass, call.Common(), config)
+			if !isStart {
+				continue
+			}
+
+			// Is the span, or a value it flows to, returned?
+			aliases := newValueAliases(fn, getSpanValue(call, lib))
+			for _, b := range fn.Blocks {
+				ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+				if !ok {
+					continue
+				}
+
+				for _, r := range ret.Results {
+					if aliases.has(r) {
+						return lib, true
+					}
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// exportSpanParamFacts exports a spanParamFact for each function in the package
+// that calls End, SetStatus, or RecordError on a span parameter on all paths.
+//
+// Functions in the package may pass their spans on to each other, so facts are
+// recomputed until none change.
+func exportSpanParamFacts(pass *analysis.Pass, info *ssaInfo, config *Config) {
+	decls := getFuncDecls(pass)
+
+	for changed := true; changed; {
+		changed = false
+		for _, decl := range decls {
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok || info.funcs[decl] == nil {
+				continue
+			}
+
+			fact := getSpanParamFact(pass, info, info.funcs[decl], config)
+			if fact == nil {
+				continue
+			}
+
+			var prev spanParamFact
+			if pass.ImportObjectFact(fn, &prev) && prev.String() == fact.String() {
+				continue
+			}
+
+			pass.ExportObjectFact(fn, fact)
+			changed = true
+		}
+	}
+}
+
+// getSpanParamFact returns the selectors called on each of the function's span
+// parameters on all paths, or nil if there are none.
+func getSpanParamFact(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, config *Config) *spanParamFact {
+	// The receiver, if any, is the first SSA parameter.
+	params := fn.Params
+	if fn.Signature.Recv() != nil && len(params) > 0 {
+		params = params[1:]
+	}
+
+	fact := &spanParamFact{Calls: map[int][]string{}}
+	for i, p := range params {
+		lib := config.getParamSpanLibrary(p.Type())
+		if lib == nil {
+			continue
+		}
+
+		// Only calls that set an error status count for the set-status selector. Selectors
+		// shared with End, like seg.Close(err) in AWS X-Ray, must be called both ways.
+		selectors := []struct {
+			name        string
+			checkStatus bool
+		}{
+			{lib.EndMethod, false},
+			{lib.setStatusName(), true},
+			{lib.RecordErrorMethod, false},
+		}
+
+		called := make(map[string]bool)
+		var names []string
+		for _, sel := range selectors {
+			if sel.name == "" {
+				continue
+			}
+
+			s := &callSearch{
+				pass:        pass,
+				info:        info,
+				aliases:     newValueAliases(fn, p),
+				selName:     sel.name,
+				checkStatus: sel.checkStatus,
+				lib:         lib,
+				anyPath:     sel.checkStatus || sel.name != lib.EndMethod,
+			}
+			missing := s.findMissingReturn(fn, nil, func(_ *analysis.Pass, ret *ast.ReturnStmt, _ *ssa.Return) *ast.ReturnStmt { return ret }) != nil
+
+			if prev, seen := called[sel.name]; seen {
+				called[sel.name] = prev && !missing
+				continue
+			}
+			called[sel.name] = !missing
+			names = append(names, sel.name)
+		}
+
+		for _, name := range names {
+			if called[name] {
+				fact.Calls[i] = append(fact.Calls[i], name)
+			}
+		}
+	}
+
+	if len(fact.Calls) == 0 {
+		return nil
+	}
+
+	return fact
+}
+
+// getFuncDecls returns the declarations of functions with bodies in the package.
+func getFuncDecls(pass *analysis.Pass) []*ast.FuncDecl {
+	var decls []*ast.FuncDecl
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				decls = append(decls, decl)
+			}
+		}
+	}
+
+	return decls
+}
+
+// callsSpanParam reports whether call passes the span to a function
+// that calls selName on it on all paths.
+func callsSpanParam(pass *analysis.Pass, call *ssa.CallCommon, aliases *valueAliases, selName string) bool {
+	callee := call.StaticCallee()
+	if callee == nil {
+		return false
+	}
+
+	fn, ok := callee.Object().(*types.Func)
+	if !ok {
+		return false
+	}
+
+	var fact spanParamFact
+	if !pass.ImportObjectFact(fn, &fact) {
+		return false
+	}
+
+	// The receiver, if any, is the first argument.
+	args := call.Args
+	if callee.Signature.Recv() != nil && len(args) > 0 {
+		args = args[1:]
+	}
+
+	for i, arg := range args {
+		if !aliases.has(arg) {
+			continue
+		}
+
+		for _, name := range fact.Calls[i] {
+			if name == selName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
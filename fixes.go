@@ -0,0 +1,294 @@
+package spancheck
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	otelCodesPath       = "go.opentelemetry.io/otel/codes"
+	otelCodesName       = "codes"
+	openCensusTracePath = "go.opencensus.io/trace"
+	openCensusTraceName = "trace"
+)
+
+// getEndFixes returns a fix that defers End on the span right after it is started.
+//
+// No fix is offered if End is already called on the span somewhere in the
+// function, since deferring it as well would end the span twice, or if End
+// takes arguments, like seg.Close(err) in AWS X-Ray.
+func getEndFixes(pass *analysis.Pass, node ast.Node, sv spanVar) []analysis.SuggestedFix {
+	if !sv.insertPos.IsValid() || callsSelector(pass, node, sv, sv.lib.EndMethod) || hasRequiredParams(sv.vr.Type(), sv.lib.EndMethod) {
+		return nil
+	}
+
+	call := fmt.Sprintf("defer %s.%s()", sv.vr.Name(), sv.lib.EndMethod)
+	pos := getLineEnd(pass, sv.insertPos)
+
+	return []analysis.SuggestedFix{{
+		Message: "Add " + call,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     pos,
+			End:     pos,
+			NewText: []byte("\n" + getIndent(pass, sv.stmt.Pos()) + call),
+		}},
+	}}
+}
+
+// getSetStatusFixes returns a fix that sets an error status on the span before ret.
+func getSetStatusFixes(pass *analysis.Pass, sv spanVar, ret *ast.ReturnStmt) []analysis.SuggestedFix {
+	errExpr := getReturnedError(pass, ret)
+	file := getFile(pass, ret.Pos())
+	if errExpr == "" || file == nil || sv.lib.getSetStatusCall == nil {
+		return nil
+	}
+
+	call, imports, ok := sv.lib.getSetStatusCall(pass, file, ret.Pos(), sv.vr.Name(), errExpr)
+	if !ok {
+		return nil
+	}
+
+	return []analysis.SuggestedFix{{
+		Message:   "Add " + call,
+		TextEdits: append(imports, getInsertBeforeEdit(pass, ret, call)),
+	}}
+}
+
+// getOpenTelemetrySetStatusCall returns span.SetStatus(codes.Error, err.Error()), importing
+// the codes package if needed.
+func getOpenTelemetrySetStatusCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, otelCodesPath, otelCodesName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.%s(%s.Error, %s.Error())", span, selNameSetStatus, name, errExpr), edits, true
+}
+
+// getOpenCensusSetStatusCall returns span.SetStatus(trace.Status{...}), importing the
+// trace package if needed.
+func getOpenCensusSetStatusCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, openCensusTracePath, openCensusTraceName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.%s(%s.Status{Code: %s.StatusCodeUnknown, Message: %s.Error()})", span, selNameSetStatus, name, name, errExpr), edits, true
+}
+
+// getRecordErrorFixes returns a fix that records the returned error on the span before ret.
+func getRecordErrorFixes(pass *analysis.Pass, sv spanVar, ret *ast.ReturnStmt) []analysis.SuggestedFix {
+	errExpr := getReturnedError(pass, ret)
+	if errExpr == "" {
+		return nil
+	}
+
+	call := fmt.Sprintf("%s.%s(%s)", sv.vr.Name(), sv.lib.RecordErrorMethod, errExpr)
+	var imports []analysis.TextEdit
+	if sv.lib.getRecordErrorCall != nil {
+		file := getFile(pass, ret.Pos())
+		if file == nil {
+			return nil
+		}
+
+		var ok bool
+		if call, imports, ok = sv.lib.getRecordErrorCall(pass, file, ret.Pos(), sv.vr.Name(), errExpr); !ok {
+			return nil
+		}
+	}
+
+	return []analysis.SuggestedFix{{
+		Message:   "Add " + call,
+		TextEdits: append(imports, getInsertBeforeEdit(pass, ret, call)),
+	}}
+}
+
+// getBackgroundContextFixes returns a fix that replaces the empty context passed to a
+// span start with the context in scope.
+func getBackgroundContextFixes(arg ast.Expr, ctx *types.Var) []analysis.SuggestedFix {
+	return []analysis.SuggestedFix{{
+		Message: "Replace " + types.ExprString(arg) + " with " + ctx.Name(),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     arg.Pos(),
+			End:     arg.End(),
+			NewText: []byte(ctx.Name()),
+		}},
+	}}
+}
+
+// getReturnedError returns the source of the error returned by ret. Only
+// identifiers and selectors are returned, since other expressions may have
+// side effects or be expensive to evaluate twice.
+func getReturnedError(pass *analysis.Pass, ret *ast.ReturnStmt) string {
+	for _, r := range ret.Results {
+		if isErrorType(pass.TypesInfo.TypeOf(r)) && isSimpleExpr(r) {
+			return types.ExprString(r)
+		}
+	}
+
+	return ""
+}
+
+func isSimpleExpr(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSimpleExpr(expr.X)
+	case *ast.ParenExpr:
+		return isSimpleExpr(expr.X)
+	}
+
+	return false
+}
+
+// getInsertBeforeEdit returns an edit that inserts stmt on its own line before node.
+func getInsertBeforeEdit(pass *analysis.Pass, node ast.Node, stmt string) analysis.TextEdit {
+	return analysis.TextEdit{
+		Pos:     node.Pos(),
+		End:     node.Pos(),
+		NewText: []byte(stmt + "\n" + getIndent(pass, node.Pos())),
+	}
+}
+
+// getImportName returns the name the package with path can be referred to by at pos.
+// If the file does not import the package, it returns name along with the edits
+// that import it. It returns false if name is already taken by something else.
+func getImportName(pass *analysis.Pass, file *ast.File, pos token.Pos, path, name string) (string, []analysis.TextEdit, bool) {
+	scope := pass.TypesInfo.Scopes[file].Innermost(pos)
+	if scope == nil {
+		return "", nil, false
+	}
+
+	for _, spec := range file.Imports {
+		pkgName := pass.TypesInfo.PkgNameOf(spec)
+		if pkgName == nil || pkgName.Imported().Path() != path {
+			continue
+		}
+
+		if _, obj := scope.LookupParent(pkgName.Name(), pos); obj == pkgName {
+			return pkgName.Name(), nil, true
+		}
+	}
+
+	if _, obj := scope.LookupParent(name, pos); obj != nil {
+		return "", nil, false
+	}
+
+	return name, []analysis.TextEdit{getAddImportEdit(file, path)}, true
+}
+
+// getAddImportEdit returns an edit that adds an import of path to the file.
+func getAddImportEdit(file *ast.File, path string) analysis.TextEdit {
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+
+		if decl.Rparen.IsValid() {
+			return analysis.TextEdit{
+				Pos:     decl.Rparen,
+				End:     decl.Rparen,
+				NewText: []byte("\t" + strconv.Quote(path) + "\n"),
+			}
+		}
+
+		return analysis.TextEdit{
+			Pos:     decl.End(),
+			End:     decl.End(),
+			NewText: []byte("\nimport " + strconv.Quote(path)),
+		}
+	}
+
+	return analysis.TextEdit{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + strconv.Quote(path)),
+	}
+}
+
+// getFile returns the file containing pos.
+func getFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// hasRequiredParams reports whether the method of t named name takes parameters
+// other than variadic ones, like span options.
+func hasRequiredParams(t types.Type, name string) bool {
+	sel := types.NewMethodSet(t).Lookup(nil, name)
+	if sel == nil {
+		return false
+	}
+
+	sig := sel.Type().(*types.Signature)
+	required := sig.Params().Len()
+	if sig.Variadic() {
+		required--
+	}
+
+	return required > 0
+}
+
+// callsSelector reports whether the selName method is referenced on the span anywhere in node.
+func callsSelector(pass *analysis.Pass, node ast.Node, sv spanVar, selName string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == selName {
+			id, ok := sel.X.(*ast.Ident)
+			found = ok && pass.TypesInfo.Uses[id] == sv.vr
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// getLineEnd returns the position of the newline ending the line containing pos,
+// so that insertions after pos do not move trailing comments.
+func getLineEnd(pass *analysis.Pass, pos token.Pos) token.Pos {
+	file := pass.Fset.File(pos)
+	if file == nil || file.Line(pos) >= file.LineCount() {
+		return pos
+	}
+
+	return file.LineStart(file.Line(pos)+1) - 1
+}
+
+// getIndent returns the leading whitespace of the line containing pos.
+func getIndent(pass *analysis.Pass, pos token.Pos) string {
+	file := pass.Fset.File(pos)
+	if file == nil {
+		return ""
+	}
+
+	content, err := pass.ReadFile(file.Name())
+	if err != nil {
+		return ""
+	}
+
+	start := file.Offset(file.LineStart(file.Line(pos)))
+	if start > len(content) {
+		return ""
+	}
+
+	line := content[start:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
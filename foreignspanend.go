@@ -0,0 +1,60 @@
+package spancheck
+
+import (
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkForeignSpanEnd reports calls that end spans taken from a context. The spans
+// belong to the code that started them, which still uses them and ends them itself.
+func checkForeignSpanEnd(pass *analysis.Pass, info *ssaInfo, config *Config) {
+	reported := make(map[ssa.CallInstruction]bool)
+	for _, fn := range info.funcs {
+		calls := info.getCallExprs(fn)
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				source, ok := instr.(*ssa.Call)
+				if !ok {
+					continue
+				}
+
+				callee := source.Common().StaticCallee()
+				if callee == nil || callee.Object() == nil {
+					continue
+				}
+
+				// Span from tracer.SpanFromContext(ctx), which may also return whether there is one.
+				lib := config.getForeignSpanLibrary(callee.Object())
+				if lib == nil || !lib.hasCheck(ForeignSpanEndCheck) {
+					continue
+				}
+
+				val := getSpanValue(source, lib)
+				if val == nil {
+					continue
+				}
+
+				s := &callSearch{
+					pass:    pass,
+					info:    info,
+					aliases: newValueAliases(fn, val),
+					selName: lib.EndMethod,
+				}
+
+				for _, b := range fn.Blocks {
+					for _, instr := range b.Instrs {
+						end, ok := instr.(ssa.CallInstruction)
+						if !ok || reported[end] || !s.endsSpan(end) {
+							continue
+						}
+						reported[end] = true
+
+						if call, ok := calls[end.Common().Pos()]; ok {
+							pass.ReportRangef(call, "span from %s.%s is ended, it is owned by the code that started it", callee.Object().Pkg().Name(), callee.Name())
+						}
+					}
+				}
+			}
+		}
+	}
+}
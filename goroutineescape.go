@@ -0,0 +1,236 @@
+package spancheck
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	errgroupPath  = "golang.org/x/sync/errgroup"
+	waitGroupPath = "sync"
+	groupWait     = "Wait"
+	waitGroupDone = "Done"
+)
+
+// checkGoroutineEscape reports goroutines, started with go statements, errgroup, or
+// sync.WaitGroup, that use the span while the function defers End on it. The goroutines
+// may still use the span after the function returned and ended it, unless their group
+// is waited for.
+func checkGoroutineEscape(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.EndMethod,
+		lib:     sv.lib,
+	}
+
+	if !s.defersEnd(fn) {
+		return
+	}
+
+	calls := info.getCallExprs(fn)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			var escapes bool
+			switch instr := instr.(type) {
+			case *ssa.Go:
+				// go func() { defer wg.Done(); ... }()
+				escapes = s.usesSpanAsync(instr.Common()) && !s.waitsForDone(fn, instr)
+			case *ssa.Call:
+				// g.Go(func() error { ... }), wg.Go(func() { ... })
+				if f := getGroupFunc(instr.Common()); f != nil {
+					escapes = s.usesSpanAsync(&ssa.CallCommon{Value: f}) && !s.waitsForGroup(fn, instr)
+				}
+			}
+			if !escapes {
+				continue
+			}
+
+			if call, ok := calls[instr.(ssa.CallInstruction).Common().Pos()]; ok {
+				pass.ReportRangef(call, "%s is used by a goroutine, which may run after the deferred %s.%s", sv.vr.Name(), sv.vr.Name(), s.selName)
+			}
+		}
+	}
+}
+
+// defersEnd reports whether fn defers a call that ends the span.
+func (s *callSearch) defersEnd(fn *ssa.Function) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if d, ok := instr.(*ssa.Defer); ok && s.endsSpan(d) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// usesSpanAsync reports whether call, made in a goroutine, uses the span: it calls a
+// method on the span, is passed the span, or calls a function literal that uses the span.
+// Methods that only read the span are not counted.
+func (s *callSearch) usesSpanAsync(call *ssa.CallCommon) bool {
+	if method := s.getSpanMethod(call); method != "" {
+		return !s.lib.isReadOnlyMethod(method)
+	}
+
+	for _, arg := range call.Args {
+		if s.aliases.has(arg) {
+			return true
+		}
+	}
+
+	mc, ok := call.Value.(*ssa.MakeClosure)
+	if !ok {
+		return false
+	}
+
+	fn, aliases := s.aliases.forClosure(mc)
+	if fn == nil {
+		return false
+	}
+
+	closure := *s
+	closure.aliases = aliases
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+
+			if method := closure.getSpanMethod(call.Common()); method != "" {
+				if !s.lib.isReadOnlyMethod(method) {
+					return true
+				}
+				continue
+			}
+
+			// The span is passed to another function.
+			for _, arg := range call.Common().Args {
+				if aliases.has(arg) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// waitsForGroup reports whether the group of call, a call to (*errgroup.Group).Go or TryGo,
+// or (*sync.WaitGroup).Go, is waited for on all paths to return after call. Its goroutines
+// are then done before the deferred End.
+func (s *callSearch) waitsForGroup(fn *ssa.Function, call *ssa.Call) bool {
+	group := &callSearch{
+		pass:    s.pass,
+		info:    s.info,
+		aliases: newValueAliases(fn, call.Common().Args[0]),
+		selName: groupWait,
+	}
+
+	return group.findMissingCall(fn, call, 0, func(*ssa.Return) bool { return true }) == nil
+}
+
+// waitsForDone reports whether the goroutine started by g marks a sync.WaitGroup, captured
+// from fn, done, and the WaitGroup is waited for on all paths to return after g.
+func (s *callSearch) waitsForDone(fn *ssa.Function, g *ssa.Go) bool {
+	mc, ok := g.Call.Value.(*ssa.MakeClosure)
+	if !ok {
+		return false
+	}
+
+	for _, binding := range mc.Bindings {
+		if !isWaitGroup(binding.Type()) {
+			continue
+		}
+
+		// The WaitGroup may be captured through a pointer variable that is only set once.
+		wg := &callSearch{
+			pass:    s.pass,
+			info:    s.info,
+			aliases: newValueAliases(fn, binding, getSingleStore(binding)),
+			selName: groupWait,
+		}
+
+		closureFn, aliases := wg.aliases.forClosure(mc)
+		if closureFn == nil {
+			return false
+		}
+
+		done := *wg
+		done.aliases = aliases
+		if !done.callsIn(closureFn, waitGroupDone) {
+			continue
+		}
+
+		if wg.findMissingCall(fn, g, 0, func(*ssa.Return) bool { return true }) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callsIn reports whether fn calls the selName method on the value, on any path.
+func (s *callSearch) callsIn(fn *ssa.Function, selName string) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if call, ok := instr.(ssa.CallInstruction); ok && s.callsMethod(call.Common(), selName) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getGroupFunc returns the function passed to (*errgroup.Group).Go or TryGo, or
+// (*sync.WaitGroup).Go, by call, if any.
+func getGroupFunc(call *ssa.CallCommon) ssa.Value {
+	callee := call.StaticCallee()
+	if callee == nil || len(call.Args) != 2 {
+		return nil
+	}
+
+	fn, ok := callee.Object().(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return nil
+	}
+
+	switch fn.Pkg().Path() {
+	case errgroupPath:
+		switch fn.Name() {
+		case "Go", "TryGo":
+			return call.Args[1]
+		}
+	case waitGroupPath:
+		if fn.Name() == "Go" && isWaitGroup(call.Args[0].Type()) {
+			return call.Args[1]
+		}
+	}
+
+	return nil
+}
+
+// isWaitGroup reports whether t is a sync.WaitGroup, or a pointer to one.
+func isWaitGroup(t types.Type) bool {
+	for {
+		ptr, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = ptr.Elem()
+	}
+
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == waitGroupPath && obj.Name() == "WaitGroup"
+}
@@ -0,0 +1,338 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	selNameEnd         = "End"
+	selNameSetStatus   = "SetStatus"
+	selNameRecordError = "RecordError"
+	selNameClose       = "Close"
+
+	openTelemetryName = "opentelemetry"
+)
+
+// spanType differentiates span types. It is the position of the span's library in
+// DefaultSpanLibraries, starting at one.
+type spanType int
+
+// SpanTypes is a list of all span types by name.
+//
+// Deprecated: span types are the names of the DefaultSpanLibraries, which also describe
+// their spans.
+var SpanTypes = getSpanTypes()
+
+func getSpanTypes() map[string]spanType {
+	libs := DefaultSpanLibraries()
+	spanTypes := make(map[string]spanType, len(libs))
+	for i, lib := range libs {
+		spanTypes[lib.Name] = spanType(i + 1)
+	}
+
+	return spanTypes
+}
+
+// SpanLibrary describes a tracing library whose spans are checked: the functions that
+// start its spans, and the methods that end them and record errors on them.
+type SpanLibrary struct {
+	// Name identifies the library, like "opentelemetry". It is the telemetry type
+	// of extra start span signatures.
+	Name string `json:"name"`
+
+	// StartSpanSignatures are regexes for the signatures of functions that start spans,
+	// like `\(go.opentelemetry.io/otel/trace.Tracer\).Start`.
+	StartSpanSignatures []string `json:"start-span-signatures"`
+
+	// SpanTypes are the types of the library's spans, like "go.opentelemetry.io/otel/trace.Span".
+	// They tell spans apart from the other results of span starts, and spans passed to functions.
+	SpanTypes []string `json:"span-types"`
+
+	// EndMethod is the span method that ends the span.
+	EndMethod string `json:"end-method"`
+
+	// SetStatusMethod is the span method that sets an error status. If it and SetStatusField
+	// are empty, the set-status and status-consistency checks don't apply to the library.
+	SetStatusMethod string `json:"set-status-method"`
+
+	// SetStatusField is the span field that is assigned an error status, like Status in
+	// Sentry, for libraries without a SetStatusMethod.
+	SetStatusField string `json:"set-status-field"`
+
+	// RecordErrorMethod is the span method that records an error. If it is empty,
+	// the record-error and record-error-match checks don't apply to the library.
+	RecordErrorMethod string `json:"record-error-method"`
+
+	// ReadOnlyMethods are span methods that don't change the span, like SpanContext. They
+	// can still be called after the span has ended, and from goroutines.
+	ReadOnlyMethods []string `json:"read-only-methods"`
+
+	// ForeignSpanSignatures are regexes for the signatures of functions that return a span
+	// started by someone else, like `go.opentelemetry.io/otel/trace.SpanFromContext`.
+	ForeignSpanSignatures []string `json:"foreign-span-signatures"`
+
+	// Checks are the names of the checks that apply to the library's spans, if they are
+	// enabled. All checks apply if it is empty.
+	Checks []string `json:"checks"`
+
+	// checks are the parsed Checks.
+	checks []Check
+
+	// foreignSpanSignatures are the compiled ForeignSpanSignatures.
+	foreignSpanSignatures []*regexp.Regexp
+
+	// callsMethod reports whether call stands for a call to selName, one of the library's
+	// span methods, like Finish(tracer.WithError(err)) sets an error status in Datadog.
+	// method is the span method that call calls, or empty if the span is passed to call.
+	// known reports whether the library knows call; calls it doesn't know may still count
+	// through the facts of the called function. It is nil for libraries where only calls
+	// to the methods count.
+	callsMethod func(info *types.Info, selName, method string, call *ast.CallExpr) (calls, known bool)
+
+	// getSetStatusCall returns the call that sets an error status, with the error errExpr,
+	// on the span named span, and the edits importing what the call needs. It is nil for
+	// libraries without a suggested fix.
+	getSetStatusCall func(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool)
+
+	// getRecordErrorCall is like getSetStatusCall, for the call that records an error. It
+	// is nil for libraries that record errors with span.RecordErrorMethod(err).
+	getRecordErrorCall func(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool)
+}
+
+// DefaultSpanLibraries returns the built-in span libraries: OpenTelemetry, OpenCensus, Datadog,
+// OpenTracing, AWS X-Ray and Sentry.
+func DefaultSpanLibraries() []SpanLibrary {
+	return []SpanLibrary{
+		{
+			Name: openTelemetryName,
+			StartSpanSignatures: []string{
+				// https://github.com/open-telemetry/opentelemetry-go/blob/98b32a6c3a87fbee5d34c063b9096f416b250897/trace/trace.go#L523
+				`\(go.opentelemetry.io/otel/trace.Tracer\).Start`,
+			},
+			SpanTypes:         []string{"go.opentelemetry.io/otel/trace.Span"},
+			EndMethod:         selNameEnd,
+			SetStatusMethod:   selNameSetStatus,
+			RecordErrorMethod: selNameRecordError,
+			ReadOnlyMethods:   []string{"IsRecording", "SpanContext", "TracerProvider"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/go.opentelemetry.io/otel/trace#SpanFromContext
+				`go.opentelemetry.io/otel/trace.SpanFromContext`,
+			},
+			getSetStatusCall: getOpenTelemetrySetStatusCall,
+		},
+		{
+			Name: "opencensus",
+			StartSpanSignatures: []string{
+				// https://pkg.go.dev/go.opencensus.io/trace#StartSpan
+				`go.opencensus.io/trace.StartSpan`,
+				// https://github.com/census-instrumentation/opencensus-go/blob/v0.24.0/trace/trace_api.go#L66
+				`go.opencensus.io/trace.StartSpanWithRemoteParent`,
+			},
+			SpanTypes:       []string{"*go.opencensus.io/trace.Span"},
+			EndMethod:       selNameEnd,
+			SetStatusMethod: selNameSetStatus,
+			// RecordError only exists in OpenTelemetry.
+			ReadOnlyMethods: []string{"IsRecordingEvents", "SpanContext", "String"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/go.opencensus.io/trace#FromContext
+				`go.opencensus.io/trace.FromContext`,
+			},
+			getSetStatusCall: getOpenCensusSetStatusCall,
+		},
+		{
+			Name: "datadog",
+			StartSpanSignatures: []string{
+				// https://pkg.go.dev/gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer#StartSpan
+				// https://pkg.go.dev/gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer#StartSpanFromContext
+				`gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer.StartSpan`,
+			},
+			SpanTypes: []string{"gopkg.in/DataDog/dd-trace-go.v1/ddtrace.Span"},
+			EndMethod: selNameFinish,
+			// Errors are set with span.SetTag(ext.Error, err), or span.Finish(tracer.WithError(err)).
+			SetStatusMethod: selNameSetTag,
+			ReadOnlyMethods: []string{"BaggageItem", "Context"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer#SpanFromContext
+				`gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer.SpanFromContext`,
+			},
+			callsMethod:      datadogCallsMethod,
+			getSetStatusCall: getDatadogSetStatusCall,
+		},
+		{
+			Name: "opentracing",
+			StartSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/opentracing/opentracing-go#StartSpan
+				// https://pkg.go.dev/github.com/opentracing/opentracing-go#StartSpanFromContext
+				`github.com/opentracing/opentracing-go.StartSpan`,
+				// https://pkg.go.dev/github.com/opentracing/opentracing-go#Tracer
+				`\(github.com/opentracing/opentracing-go.Tracer\).StartSpan`,
+			},
+			SpanTypes: []string{"github.com/opentracing/opentracing-go.Span"},
+			EndMethod: selNameFinish,
+			// Errors are set with ext.Error.Set(span, true), and logged with span.LogFields(log.Error(err)).
+			// ext.LogError(span, err) does both.
+			SetStatusMethod:   selNameSetTag,
+			RecordErrorMethod: selNameLogFields,
+			ReadOnlyMethods:   []string{"BaggageItem", "Context", "Tracer"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/opentracing/opentracing-go#SpanFromContext
+				`github.com/opentracing/opentracing-go.SpanFromContext`,
+			},
+			callsMethod:        openTracingCallsMethod,
+			getSetStatusCall:   getOpenTracingSetStatusCall,
+			getRecordErrorCall: getOpenTracingRecordErrorCall,
+		},
+		{
+			Name: "xray",
+			StartSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/aws/aws-xray-sdk-go/xray#BeginSegment
+				`github.com/aws/aws-xray-sdk-go/xray.BeginSegment`,
+				// https://pkg.go.dev/github.com/aws/aws-xray-sdk-go/xray#BeginSubsegment
+				`github.com/aws/aws-xray-sdk-go/xray.BeginSubsegment`,
+			},
+			SpanTypes: []string{"*github.com/aws/aws-xray-sdk-go/xray.Segment"},
+			// Close(err) ends the segment and records the error, if it is not nil.
+			EndMethod:       selNameClose,
+			SetStatusMethod: selNameClose,
+			ReadOnlyMethods: []string{"DownstreamHeader", "GetConfiguration"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/aws/aws-xray-sdk-go/xray#GetSegment, like the
+				// segment xray.Capture passes to its callback.
+				`github.com/aws/aws-xray-sdk-go/xray.GetSegment`,
+			},
+		},
+		{
+			Name: "sentry",
+			StartSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/getsentry/sentry-go#StartSpan
+				`github.com/getsentry/sentry-go.StartSpan`,
+				// https://pkg.go.dev/github.com/getsentry/sentry-go#StartTransaction
+				`github.com/getsentry/sentry-go.StartTransaction`,
+				// https://pkg.go.dev/github.com/getsentry/sentry-go#Span.StartChild
+				`\(\*github.com/getsentry/sentry-go.Span\).StartChild`,
+			},
+			SpanTypes: []string{"*github.com/getsentry/sentry-go.Span"},
+			EndMethod: selNameFinish,
+			// Errors are set with span.Status = sentry.SpanStatusInternalError.
+			SetStatusField:  sentryStatusField,
+			ReadOnlyMethods: []string{"Context", "GetTransaction", "IsTransaction", "ToBaggage", "ToSentryTrace"},
+			ForeignSpanSignatures: []string{
+				// https://pkg.go.dev/github.com/getsentry/sentry-go#SpanFromContext
+				`github.com/getsentry/sentry-go.SpanFromContext`,
+				// https://pkg.go.dev/github.com/getsentry/sentry-go#TransactionFromContext
+				`github.com/getsentry/sentry-go.TransactionFromContext`,
+			},
+			getSetStatusCall: getSentrySetStatusCall,
+		},
+	}
+}
+
+// hasCheck reports whether check applies to the library's spans. Checks of methods the
+// library doesn't have never apply.
+func (l *SpanLibrary) hasCheck(check Check) bool {
+	switch check {
+	case SetStatusCheck, StatusConsistencyCheck:
+		if l.setStatusName() == "" {
+			return false
+		}
+	case RecordErrorCheck, RecordErrorMatchCheck:
+		if l.RecordErrorMethod == "" {
+			return false
+		}
+	}
+
+	return len(l.checks) == 0 || contains(l.checks, check)
+}
+
+// setStatusName returns the name of the span method, or field, that sets an error status.
+func (l *SpanLibrary) setStatusName() string {
+	if l.SetStatusMethod != "" {
+		return l.SetStatusMethod
+	}
+
+	return l.SetStatusField
+}
+
+// isSpanType reports whether t is the type of one of the library's spans.
+func (l *SpanLibrary) isSpanType(t types.Type) bool {
+	name := t.String()
+	for _, spanType := range l.SpanTypes {
+		if spanType == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isReadOnlyMethod reports whether method is one of the library's read-only span methods.
+func (l *SpanLibrary) isReadOnlyMethod(method string) bool {
+	for _, m := range l.ReadOnlyMethods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isForeignSpanSource reports whether fn returns a span of the library that it did not start.
+func (l *SpanLibrary) isForeignSpanSource(fn types.Object) bool {
+	for _, signature := range l.foreignSpanSignatures {
+		if signature.MatchString(fn.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSpanPackages returns the import paths of the packages of the library's span types.
+func (l *SpanLibrary) getSpanPackages() []string {
+	paths := make([]string, 0, len(l.SpanTypes))
+	for _, spanType := range l.SpanTypes {
+		name := strings.TrimLeft(spanType, "*")
+		if i := strings.LastIndex(name, "."); i > 0 {
+			paths = append(paths, name[:i])
+		}
+	}
+
+	return paths
+}
+
+// finalize validates the library and parses its checks. It reports whether the library is valid.
+func (l *SpanLibrary) finalize() bool {
+	if l.Name == "" || l.EndMethod == "" || len(l.SpanTypes) == 0 {
+		log.Default().Printf("[WARN] invalid span library \"%s\". expected a name, span types and an end method\n", l.Name)
+
+		return false
+	}
+
+	l.checks = nil
+	for _, name := range l.Checks {
+		check, ok := Checks[strings.TrimSpace(name)]
+		if !ok {
+			log.Default().Printf("[WARN] invalid check \"%s\" for span library \"%s\"\n", name, l.Name)
+
+			continue
+		}
+
+		l.checks = append(l.checks, check)
+	}
+
+	l.foreignSpanSignatures = nil
+	for _, sig := range l.ForeignSpanSignatures {
+		if regex := compileSignature("foreign span", sig); regex != nil {
+			l.foreignSpanSignatures = append(l.foreignSpanSignatures, regex)
+		}
+	}
+
+	return true
+}
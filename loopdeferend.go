@@ -0,0 +1,131 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkLoopDeferEnd reports spans started in a loop body that are not ended before
+// the next iteration, and deferred calls to End on them. Deferred calls run when the
+// function returns, so every span started by the loop stays open until then.
+func checkLoopDeferEnd(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	loop := getLoopBody(getFuncBody(fn), sv.call.Pos())
+	if loop == nil {
+		return
+	}
+
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.EndMethod,
+	}
+
+	calls := info.getCallExprs(fn)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			d, ok := instr.(*ssa.Defer)
+			if !ok || !s.endsSpan(d) {
+				continue
+			}
+
+			call, ok := calls[d.Common().Pos()]
+			if !ok || call.Pos() < loop.Pos() || call.Pos() >= loop.End() {
+				continue
+			}
+
+			pass.ReportRangef(call, "%s.%s is deferred in a loop, spans are not ended until the function returns", sv.vr.Name(), s.selName)
+		}
+	}
+
+	if s.reachesBackEdge(sv.start) {
+		pass.ReportRangef(sv.stmt, "%s.%s is not called before the next loop iteration", sv.vr.Name(), s.selName)
+	}
+}
+
+// reachesBackEdge reports whether a back edge of a loop containing start can be reached
+// from start without ending the span. Deferred calls don't end the span before the edge.
+func (s *callSearch) reachesBackEdge(start ssa.Instruction) bool {
+	startBlock := start.Block()
+
+	// ends reports whether instrs end the span, or never return.
+	ends := func(instrs []ssa.Instruction) bool {
+		for _, instr := range instrs {
+			if instr == start {
+				return true // the span is started anew
+			}
+
+			call, ok := instr.(*ssa.Call)
+			if ok && (s.endsSpan(call) || s.info.noReturn[call.Pos()]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	rest := startBlock.Instrs
+	for i, instr := range rest {
+		if instr == start {
+			rest = rest[i+1:]
+			break
+		}
+	}
+
+	seen := make(map[*ssa.BasicBlock]bool)
+	var search func(b *ssa.BasicBlock, instrs []ssa.Instruction) bool
+	search = func(b *ssa.BasicBlock, instrs []ssa.Instruction) bool {
+		if ends(instrs) {
+			return false
+		}
+
+		for _, succ := range b.Succs {
+			// A back edge goes to the header of a loop, which dominates the edge's source.
+			if succ.Dominates(b) && succ.Dominates(startBlock) {
+				return true
+			}
+
+			if !seen[succ] {
+				seen[succ] = true
+				if search(succ, succ.Instrs) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return search(startBlock, rest)
+}
+
+// getLoopBody returns the body of the innermost for or range loop in body that contains pos.
+func getLoopBody(body *ast.BlockStmt, pos token.Pos) *ast.BlockStmt {
+	if body == nil {
+		return nil
+	}
+
+	var loop *ast.BlockStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil || n.Pos() > pos || pos >= n.End() {
+			return false // pos is outside the node
+		}
+
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // don't stray into nested functions
+		case *ast.ForStmt:
+			if n.Body.Pos() <= pos {
+				loop = n.Body
+			}
+		case *ast.RangeStmt:
+			if n.Body.Pos() <= pos {
+				loop = n.Body
+			}
+		}
+		return true
+	})
+
+	return loop
+}
@@ -0,0 +1,128 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	openTracingExtPath  = "github.com/opentracing/opentracing-go/ext"
+	openTracingExtName  = "ext"
+	openTracingLogPath  = "github.com/opentracing/opentracing-go/log"
+	openTracingErrorTag = "error" // ext.Error
+	openTracingLogError = "LogError"
+	openTracingSet      = "Set"
+
+	selNameLogFields = "LogFields"
+)
+
+// openTracingCallsMethod reports whether call stands for a call to selName on an
+// OpenTracing span. The SetTag calls that count mark the span as failed: span.SetTag("error", true),
+// ext.Error.Set(span, true), or ext.LogError(span, err). The LogFields calls that count log
+// an error: span.LogFields(log.Error(err)), or ext.LogError(span, err). Tags, values and
+// fields that are not known are assumed to. Calls on the span and to the ext package are known.
+func openTracingCallsMethod(info *types.Info, selName, method string, call *ast.CallExpr) (calls, known bool) {
+	if method == "" {
+		fn, ok := typeutil.Callee(info, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != openTracingExtPath {
+			return false, false
+		}
+
+		switch {
+		case fn.Name() == openTracingLogError:
+			return selName == selNameSetTag || selName == selNameLogFields, true
+		case fn.Name() == openTracingSet && selName == selNameSetTag:
+			// ext.Error.Set(span, true)
+			sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+			if !ok || len(call.Args) != 2 {
+				return false, true
+			}
+
+			return isOpenTracingErrorTag(info, sel.X) && !isConstFalse(info, call.Args[1]), true
+		}
+
+		return false, true
+	}
+
+	switch {
+	case selName != method:
+		return false, true
+	case method == selNameSetTag:
+		// span.SetTag(string(ext.Error), true)
+		return len(call.Args) == 2 && isOpenTracingErrorTag(info, call.Args[0]) && !isConstFalse(info, call.Args[1]), true
+	case method == selNameLogFields:
+		// span.LogFields(fields...)
+		if call.Ellipsis.IsValid() {
+			return true, true
+		}
+
+		for _, arg := range call.Args {
+			field, ok := ast.Unparen(arg).(*ast.CallExpr)
+			if !ok || getFuncName(info, field) == openTracingLogPath+".Error" {
+				return true, true
+			}
+		}
+
+		return false, true
+	}
+
+	return true, true
+}
+
+// isOpenTracingErrorTag reports whether expr may be the error tag, ext.Error. Tags that
+// are not constant, or are not from the ext package, are assumed to be.
+func isOpenTracingErrorTag(info *types.Info, expr ast.Expr) bool {
+	expr = ast.Unparen(expr)
+	if tv := info.Types[expr]; tv.Value != nil {
+		return tv.Value.Kind() == constant.String && constant.StringVal(tv.Value) == openTracingErrorTag
+	}
+
+	// string(ext.Error)
+	if conv, ok := expr.(*ast.CallExpr); ok && len(conv.Args) == 1 && info.Types[conv.Fun].IsType() {
+		expr = ast.Unparen(conv.Args[0])
+	}
+
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return true
+	}
+
+	v, ok := info.Uses[sel.Sel].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Pkg().Path() != openTracingExtPath {
+		return true
+	}
+
+	return v.Name() == "Error"
+}
+
+// isConstFalse reports whether expr is the constant false.
+func isConstFalse(info *types.Info, expr ast.Expr) bool {
+	tv := info.Types[expr]
+	return tv.Value != nil && tv.Value.Kind() == constant.Bool && !constant.BoolVal(tv.Value)
+}
+
+// getOpenTracingSetStatusCall returns ext.Error.Set(span, true), importing the ext package if needed.
+func getOpenTracingSetStatusCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, _ string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, openTracingExtPath, openTracingExtName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.Error.%s(%s, true)", name, openTracingSet, span), edits, true
+}
+
+// getOpenTracingRecordErrorCall returns ext.LogError(span, err), importing the ext package if needed.
+func getOpenTracingRecordErrorCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, errExpr string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, openTracingExtPath, openTracingExtName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.%s(%s, %s)", name, openTracingLogError, span, errExpr), edits, true
+}
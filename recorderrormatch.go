@@ -0,0 +1,245 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkRecordErrorMatch reports error returns, reached after span.RecordError(err),
+// that return an error other than err. Errors wrapping err, with fmt.Errorf and %w
+// or with errors.Join, are accepted.
+func checkRecordErrorMatch(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	body := getFuncBody(fn)
+	if body == nil {
+		return
+	}
+
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.RecordErrorMethod,
+	}
+
+	m := &errorMatcher{
+		info:    pass.TypesInfo,
+		assigns: getAssignedValues(pass.TypesInfo, body),
+	}
+	namedErr := getNamedErrorResult(pass.TypesInfo, fn)
+
+	calls := info.getCallExprs(fn)
+	returns := getReturnStmts(fn)
+	reported := make(map[*ast.ReturnStmt]bool)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok || !s.callsMethod(call.Common(), s.selName) {
+				continue
+			}
+
+			// Only errors held by variables can be compared.
+			expr, ok := calls[call.Common().Pos()]
+			if !ok || len(expr.Args) == 0 {
+				continue
+			}
+			id, ok := ast.Unparen(expr.Args[0]).(*ast.Ident)
+			if !ok {
+				continue
+			}
+			recorded, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+			if !ok {
+				continue
+			}
+
+			// Find all returns reached before an error is recorded again.
+			s.findMissingCall(fn, call, 0, func(ret *ssa.Return) bool {
+				stmt, ok := returns[ret.Pos()]
+				if !ok || reported[stmt] || getErrorReturn(pass, stmt, ret) == nil {
+					return false
+				}
+
+				// Bare returns return the named error result.
+				var node ast.Node = stmt
+				returned := getReturnedErrorExpr(pass.TypesInfo, stmt)
+				if returned != nil {
+					node = returned
+				} else if len(stmt.Results) == 0 && namedErr != nil {
+					returned = namedErr
+				}
+
+				if returned == nil || m.wraps(returned, recorded, make(map[*types.Var]bool)) {
+					return false
+				}
+				reported[stmt] = true
+
+				pass.ReportRangef(node, "returned error does not wrap %s, recorded with %s.%s", recorded.Name(), sv.vr.Name(), s.selName)
+				return false // find all returns
+			})
+		}
+	}
+}
+
+// errorMatcher matches returned error expressions with recorded errors.
+type errorMatcher struct {
+	info *types.Info
+
+	// assigns maps the variables of the function to the values assigned to them.
+	assigns map[*types.Var][]ast.Expr
+}
+
+// wraps reports whether expr may be, or wrap, the recorded error. Calls to functions
+// other than fmt.Errorf and errors.Join are assumed to wrap the error.
+func (m *errorMatcher) wraps(expr ast.Expr, recorded *types.Var, seen map[*types.Var]bool) bool {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		v, ok := m.info.ObjectOf(expr).(*types.Var)
+		if !ok {
+			return false
+		}
+		if v == recorded {
+			return true
+		}
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+
+		// Look through the values assigned to other variables, like err2 := fmt.Errorf("...: %w", err).
+		for _, val := range m.assigns[v] {
+			if m.wraps(val, recorded, seen) {
+				return true
+			}
+		}
+		return false
+	case *ast.CallExpr:
+		switch getFuncName(m.info, expr) {
+		case "fmt.Errorf":
+			if len(expr.Args) == 0 || !isWrapFormat(m.info, expr.Args[0]) {
+				return false
+			}
+			return m.anyWraps(expr.Args[1:], recorded, seen)
+		case "errors.Join":
+			return m.anyWraps(expr.Args, recorded, seen)
+		}
+	}
+
+	return true
+}
+
+func (m *errorMatcher) anyWraps(exprs []ast.Expr, recorded *types.Var, seen map[*types.Var]bool) bool {
+	for _, expr := range exprs {
+		if m.wraps(expr, recorded, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWrapFormat reports whether format is a constant string with a %w verb, or not a constant.
+func isWrapFormat(info *types.Info, format ast.Expr) bool {
+	tv, ok := info.Types[format]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return true
+	}
+
+	return strings.Contains(constant.StringVal(tv.Value), "%w")
+}
+
+// getFuncName returns the package-qualified name of the function called, like
+// "fmt.Errorf", or "" if it is not a package-level function.
+func getFuncName(info *types.Info, call *ast.CallExpr) string {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return ""
+	}
+
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+		return ""
+	}
+
+	return fn.Pkg().Path() + "." + fn.Name()
+}
+
+// getAssignedValues maps the variables assigned to in body to the single values
+// assigned to them. Values of multi-value calls are not tracked.
+func getAssignedValues(info *types.Info, body *ast.BlockStmt) map[*types.Var][]ast.Expr {
+	assigns := make(map[*types.Var][]ast.Expr)
+	add := func(lhs []ast.Expr, rhs []ast.Expr) {
+		if len(lhs) != len(rhs) {
+			return
+		}
+
+		for i, l := range lhs {
+			if id, ok := l.(*ast.Ident); ok {
+				if v, ok := info.ObjectOf(id).(*types.Var); ok {
+					assigns[v] = append(assigns[v], rhs[i])
+				}
+			}
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			add(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			add(lhs, n.Values)
+		}
+		return true
+	})
+
+	return assigns
+}
+
+// getNamedErrorResult returns the identifier of the last named error result of fn, if any.
+func getNamedErrorResult(info *types.Info, fn *ssa.Function) *ast.Ident {
+	var results *ast.FieldList
+	switch syntax := fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		results = syntax.Type.Results
+	case *ast.FuncLit:
+		results = syntax.Type.Results
+	}
+	if results == nil {
+		return nil
+	}
+
+	var named *ast.Ident
+	for _, field := range results.List {
+		for _, name := range field.Names {
+			if isErrorType(info.TypeOf(name)) {
+				named = name
+			}
+		}
+	}
+
+	return named
+}
+
+// getReturnedErrorExpr returns the error expression returned by ret. It returns nil
+// if there is none, or if it cannot be told apart, like for a call returning multiple values.
+func getReturnedErrorExpr(info *types.Info, ret *ast.ReturnStmt) ast.Expr {
+	for i := len(ret.Results) - 1; i >= 0; i-- {
+		if t := info.TypeOf(ret.Results[i]); t != nil && isErrorType(t) {
+			return ret.Results[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	sentryPath                = "github.com/getsentry/sentry-go"
+	sentryName                = "sentry"
+	sentryStatusField         = "Status"
+	sentryStatusOK            = "SpanStatusOK"
+	sentryStatusUndefined     = "SpanStatusUndefined"
+	sentryStatusInternalError = "SpanStatusInternalError"
+)
+
+// getSentryStatus returns the name of the Sentry status val, and whether it is an error
+// status: any status other than sentry.SpanStatusOK and sentry.SpanStatusUndefined.
+func getSentryStatus(pkg *types.Package, val constant.Value) (string, bool) {
+	name := ""
+	for _, n := range pkg.Scope().Names() {
+		if c, ok := pkg.Scope().Lookup(n).(*types.Const); ok && c.Type().String() == sentryPath+".SpanStatus" && constant.Compare(c.Val(), token.EQL, val) {
+			name = n
+			break
+		}
+	}
+
+	if name == "" {
+		return val.String(), true
+	}
+
+	return sentryName + "." + name, name != sentryStatusOK && name != sentryStatusUndefined
+}
+
+// getSentrySetStatusCall returns span.Status = sentry.SpanStatusInternalError, importing
+// the sentry package if needed.
+func getSentrySetStatusCall(pass *analysis.Pass, file *ast.File, pos token.Pos, span, _ string) (string, []analysis.TextEdit, bool) {
+	name, edits, ok := getImportName(pass, file, pos, sentryPath, sentryName)
+	if !ok {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf("%s.%s = %s.%s", span, sentryStatusField, name, sentryStatusInternalError), edits, true
+}
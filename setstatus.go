@@ -0,0 +1,243 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	otelCodesErrorName       = "Error"
+	openCensusStatusName     = "Status"
+	openCensusStatusCodeName = "Code"
+	openCensusStatusOKName   = "StatusCodeOK"
+)
+
+// checkSetStatus reports paths that return an error without setting an error status
+// on the span. SetStatus calls that set another status, like codes.Ok, are reported
+// on their own if they are followed by an error return.
+func checkSetStatus(
+	pass *analysis.Pass,
+	info *ssaInfo,
+	fn *ssa.Function,
+	sv spanVar,
+	checkErr func(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt,
+	ignoreCheckSig *regexp.Regexp,
+) {
+	s := &callSearch{
+		pass:           pass,
+		info:           info,
+		aliases:        newValueAliases(fn, sv.val),
+		selName:        sv.lib.setStatusName(),
+		ignoreCheckSig: ignoreCheckSig,
+		checkStatus:    true,
+		lib:            sv.lib,
+		anyPath:        true,
+	}
+
+	ret := s.findMissingReturn(fn, sv.start, checkErr)
+	if ret == nil {
+		return
+	}
+
+	calls := info.getCallExprs(fn)
+	reported := false
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case ssa.CallInstruction:
+				if s.reportDeferredError(instr, checkErr) {
+					reported = true
+					continue
+				}
+
+				if !s.callsMethod(instr.Common(), s.selName) {
+					continue
+				}
+
+				expr, ok := calls[instr.Common().Pos()]
+				if !ok {
+					continue
+				}
+
+				status, isErr := getStatus(pass.TypesInfo, expr)
+				if isErr || s.findMissingReturn(fn, instr, checkErr) == nil {
+					continue
+				}
+
+				pass.ReportRangef(expr, "%s.%s is called with %s before returning an error", sv.vr.Name(), s.selName, status)
+				reported = true
+			case *ssa.Store:
+				if !s.assignsField(instr, s.selName) {
+					continue
+				}
+
+				status, isErr := getStoredStatus(instr.Val)
+				if isErr || s.findMissingReturn(fn, instr, checkErr) == nil {
+					continue
+				}
+
+				pass.Reportf(instr.Pos(), "%s.%s is set to %s before returning an error", sv.vr.Name(), s.selName, status)
+				reported = true
+			}
+		}
+	}
+
+	// Paths with a wrong status are reported, look for paths without any status.
+	if reported {
+		s.checkStatus = false
+		if ret = s.findMissingReturn(fn, sv.start, checkErr); ret == nil {
+			return
+		}
+	}
+
+	// Statuses set through a field, like span.Status in Sentry, are set rather than called.
+	verb, missing := "called", "calling"
+	if sv.lib.SetStatusField != "" {
+		verb, missing = "set", "setting"
+	}
+
+	pass.ReportRangef(sv.stmt, "%s.%s is not %s on all paths", sv.vr.Name(), s.selName, verb)
+	pass.Report(analysis.Diagnostic{
+		Pos:            ret.Pos(),
+		End:            ret.End(),
+		Message:        fmt.Sprintf("return can be reached without %s %s.%s", missing, sv.vr.Name(), s.selName),
+		SuggestedFixes: getSetStatusFixes(pass, sv, ret),
+	})
+}
+
+// reportDeferredError reports call if it sets the status with an error variable, but is
+// deferred, like defer seg.Close(err), and an error can be returned after it. The error is
+// evaluated at the defer statement, before it is set. It reports whether call was reported.
+func (s *callSearch) reportDeferredError(call ssa.CallInstruction, checkErr func(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt) bool {
+	errVar := s.getDeferredError(call)
+	if errVar == nil {
+		return false
+	}
+
+	// Match the call as if it were made in place.
+	deferred := *s
+	deferred.checkStatus = false
+	if matches, _ := deferred.matchesCall(call); !matches || s.findMissingReturn(call.Parent(), call, checkErr) == nil {
+		return false
+	}
+
+	expr, ok := s.info.getCallExprs(call.Parent())[call.Common().Pos()]
+	if !ok {
+		return false
+	}
+
+	src := types.ExprString(expr)
+	s.pass.ReportRangef(expr, "%s is deferred with %s evaluated at the defer statement; use defer func() { %s }()", src, errVar.Name, src)
+	return true
+}
+
+// setsErrorStatus reports whether call, a SetStatus call on the span, sets an error status.
+func (s *callSearch) setsErrorStatus(call ssa.CallInstruction) bool {
+	expr, ok := s.info.getCallExprs(call.Parent())[call.Common().Pos()]
+	if !ok {
+		return true
+	}
+
+	_, isErr := getStatus(s.pass.TypesInfo, expr)
+	return isErr
+}
+
+// getStoredStatus returns the name of the status v, a value assigned to a status
+// field, and whether it is an error status. Only Sentry statuses are known, others
+// are assumed to be errors.
+func getStoredStatus(v ssa.Value) (string, bool) {
+	c, ok := v.(*ssa.Const)
+	if !ok || c.Value == nil {
+		return "", true
+	}
+
+	named, ok := types.Unalias(c.Type()).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != sentryPath {
+		return "", true
+	}
+
+	return getSentryStatus(named.Obj().Pkg(), c.Value)
+}
+
+// getStatus returns the source of the status code set by call, a call to SetStatus,
+// and whether it is an error status: codes.Error for OpenTelemetry spans, a code
+// other than trace.StatusCodeOK for OpenCensus spans, and an error other than nil
+// for AWS X-Ray segments. Codes that are not constant are assumed to be errors.
+func getStatus(info *types.Info, call *ast.CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", true
+	}
+
+	arg := ast.Unparen(call.Args[0])
+	if tv, ok := info.Types[arg]; ok && (tv.IsNil() || isErrorType(tv.Type)) {
+		// seg.Close(err) in AWS X-Ray, which sets no error status if err is nil.
+		return types.ExprString(arg), !tv.IsNil()
+	}
+
+	named, ok := types.Unalias(info.TypeOf(arg)).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return "", true
+	}
+
+	pkg := named.Obj().Pkg()
+	switch {
+	case pkg.Path() == otelCodesPath:
+		return types.ExprString(arg), isConst(info, arg, pkg, otelCodesErrorName, token.EQL)
+	case pkg.Path() == openCensusTracePath && named.Obj().Name() == openCensusStatusName:
+		lit, ok := arg.(*ast.CompositeLit)
+		if !ok {
+			return "", true
+		}
+
+		code := getStatusCode(lit)
+		if code == nil {
+			return "no " + types.ExprString(lit.Type) + "." + openCensusStatusCodeName, false // the zero value is StatusCodeOK
+		}
+
+		return types.ExprString(code), isConst(info, code, pkg, openCensusStatusOKName, token.NEQ)
+	}
+
+	return "", true
+}
+
+// getStatusCode returns the Code field of an OpenCensus trace.Status literal, or nil if it is unset.
+func getStatusCode(lit *ast.CompositeLit) ast.Expr {
+	for i, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			if i == 0 {
+				return elt // trace.Status{code, msg}
+			}
+			continue
+		}
+
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == openCensusStatusCodeName {
+			return kv.Value
+		}
+	}
+
+	return nil
+}
+
+// isConst reports whether the constant value of expr compares with op to the
+// constant name declared in pkg. Values that are not constant compare true.
+func isConst(info *types.Info, expr ast.Expr, pkg *types.Package, name string, op token.Token) bool {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil {
+		return true
+	}
+
+	c, ok := pkg.Scope().Lookup(name).(*types.Const)
+	if !ok {
+		return true
+	}
+
+	return constant.Compare(tv.Value, op, c.Val())
+}
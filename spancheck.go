@@ -1,7 +1,9 @@
 package spancheck
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"regexp"
 
@@ -9,32 +11,11 @@ import (
 	"golang.org/x/tools/go/analysis/passes/ctrlflow"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
-	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/ssa"
 )
 
 const stackLen = 32
 
-// spanType differentiates span types.
-type spanType int
-
-const (
-	spanUnset         spanType = iota // not a span
-	spanOpenTelemetry                 // from go.opentelemetry.io/otel
-	spanOpenCensus                    // from go.opencensus.io/trace
-)
-
-const (
-	selNameEnd         = "End"
-	selNameSetStatus   = "SetStatus"
-	selNameRecordError = "RecordError"
-)
-
-// SpanTypes is a list of all span types by name.
-var SpanTypes = map[string]spanType{
-	"opentelemetry": spanOpenTelemetry,
-	"opencensus":    spanOpenCensus,
-}
-
 // this approach stolen from errcheck
 // https://github.com/kisielk/errcheck/blob/7f94c385d0116ccc421fbb4709e4a484d98325ee/errcheck/errcheck.go#L22
 var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
@@ -50,13 +31,17 @@ func newAnalyzer(config *Config) *analysis.Analyzer {
 
 	return &analysis.Analyzer{
 		Name:  "spancheck",
-		Doc:   "Checks for mistakes with OpenTelemetry/Census spans.",
+		Doc:   "Checks for mistakes with spans of tracing libraries, like OpenTelemetry.",
 		Flags: config.fs,
 		Run:   run(config),
 		Requires: []*analysis.Analyzer{
 			ctrlflow.Analyzer,
 			inspect.Analyzer,
 		},
+		FactTypes: []analysis.Fact{
+			new(spanStartFact),
+			new(spanParamFact),
+		},
 	}
 }
 
@@ -64,27 +49,60 @@ func run(config *Config) func(*analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
 		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
+		info := newSSAInfo(pass, config)
+		if info == nil {
+			return nil, nil // no spans
+		}
+
+		exportSpanStartFacts(pass, info, config)
+		exportSpanParamFacts(pass, info, config)
+
 		nodeFilter := []ast.Node{
 			(*ast.FuncLit)(nil),  // f := func() {}
 			(*ast.FuncDecl)(nil), // func foo() {}
 		}
 		inspect.Preorder(nodeFilter, func(n ast.Node) {
-			runFunc(pass, n, config)
+			runFunc(pass, info, n, config)
 		})
 
+		if config.foreignSpanEndEnabled {
+			checkForeignSpanEnd(pass, info, config)
+		}
+
+		if config.tracerUsageEnabled {
+			// Tracers are only created by OpenTelemetry.
+			if lib := config.getSpanLibrary(openTelemetryName); lib == nil || lib.hasCheck(TracerUsageCheck) {
+				checkTracerUsage(pass, inspect, config.TracerNameImportPath)
+			}
+		}
+
 		return nil, nil
 	}
 }
 
 type spanVar struct {
-	stmt     ast.Node
-	id       *ast.Ident
-	vr       *types.Var
-	spanType spanType
+	stmt ast.Node
+	id   *ast.Ident
+	vr   *types.Var
+	lib  *SpanLibrary
+
+	// insertPos is where statements following the span's definition can be
+	// inserted. It is token.NoPos if the definition is not in a statement list.
+	insertPos token.Pos
+
+	// call is the span start call. It is nil for span parameters.
+	call *ast.CallExpr
+
+	// start is the call that starts the span. It is nil for span parameters,
+	// which are started before the function is entered.
+	start ssa.Instruction
+
+	// val is the span's SSA value. It is nil if the span is never used.
+	val ssa.Value
 }
 
 // runFunc checks if the node is a function, has a span, and the span never has SetStatus set.
-func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
+func runFunc(pass *analysis.Pass, info *ssaInfo, node ast.Node, config *Config) {
 	// copying https://cs.opensource.google/go/x/tools/+/master:go/analysis/passes/lostcancel/lostcancel.go
 
 	// Find scope of function node
@@ -100,6 +118,11 @@ func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
 		if config.startSpanMatchersCustomRegex != nil && config.startSpanMatchersCustomRegex.MatchString(fnSig) {
 			return
 		}
+
+		// Skip checking spans in this function if it wraps a span start.
+		if fn, ok := pass.TypesInfo.Defs[v.Name].(*types.Func); ok && pass.ImportObjectFact(fn, new(spanStartFact)) {
+			return
+		}
 	}
 
 	// Maps each span variable to its defining ValueSpec/AssignStmt.
@@ -124,7 +147,7 @@ func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
 		//   ctx, span     := otel.Tracer("app").Start(...)
 		//   ctx, span     = otel.Tracer("app").Start(...)
 		//   var ctx, span = otel.Tracer("app").Start(...)
-		sType, isStart := isSpanStart(pass.TypesInfo, n, config.startSpanMatchers)
+		lib, isStart := isSpanStart(pass, n, config)
 		if !isStart {
 			return true
 		}
@@ -133,8 +156,16 @@ func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
 			return true
 		}
 
+		if config.backgroundContextEnabled && lib.hasCheck(BackgroundContextCheck) {
+			checkBackgroundContext(pass, stack[len(stack)-2].(*ast.CallExpr))
+		}
+
+		if config.spanNameEnabled && lib.hasCheck(SpanNameCheck) {
+			checkSpanName(pass, stack[len(stack)-2].(*ast.CallExpr), config.spanNameRegex)
+		}
+
 		stmt := stack[len(stack)-3]
-		id := getID(stmt)
+		id := getID(pass.TypesInfo, stmt, stack[len(stack)-2], lib)
 		if id == nil {
 			pass.ReportRangef(n, "span is unassigned, probable memory leak")
 			return true
@@ -147,18 +178,22 @@ func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
 			// do not analyze it.
 			if funcScope.Contains(v.Pos()) {
 				spanVars[id] = spanVar{
-					vr:       v,
-					stmt:     stmt,
-					id:       id,
-					spanType: sType,
+					vr:        v,
+					stmt:      stmt,
+					id:        id,
+					lib:       lib,
+					insertPos: getInsertPos(stack[:len(stack)-2]),
+					call:      stack[len(stack)-2].(*ast.CallExpr),
 				}
 			}
 		} else if v, ok := pass.TypesInfo.Defs[id].(*types.Var); ok {
 			spanVars[id] = spanVar{
-				vr:       v,
-				stmt:     stmt,
-				id:       id,
-				spanType: sType,
+				vr:        v,
+				stmt:      stmt,
+				id:        id,
+				lib:       lib,
+				insertPos: getInsertPos(stack[:len(stack)-2]),
+				call:      stack[len(stack)-2].(*ast.CallExpr),
 			}
 		}
 
@@ -166,70 +201,190 @@ func runFunc(pass *analysis.Pass, node ast.Node, config *Config) {
 	})
 
 	if len(spanVars) == 0 {
-		return // no need to inspect CFG
+		return // no need to inspect SSA
 	}
 
-	// Obtain the CFG.
-	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
-	var g *cfg.CFG
-	var sig *types.Signature
-	switch node := node.(type) {
-	case *ast.FuncDecl:
-		sig, _ = pass.TypesInfo.Defs[node.Name].Type().(*types.Signature)
-		g = cfgs.FuncDecl(node)
-	case *ast.FuncLit:
-		sig, _ = pass.TypesInfo.Types[node.Type].Type.(*types.Signature)
-		g = cfgs.FuncLit(node)
-	}
-	if sig == nil {
-		return // missing type information
+	// Obtain the SSA function.
+	fn := info.funcs[node]
+	if fn == nil {
+		return // missing SSA
 	}
 
 	// Check for missing calls.
+	spans := make([]spanVar, 0, len(spanVars))
 	for _, sv := range spanVars {
-		if config.endCheckEnabled {
-			// Check if there's no End to the span.
-			if ret := getMissingSpanCalls(pass, g, sv, selNameEnd, func(_ *analysis.Pass, ret *ast.ReturnStmt) *ast.ReturnStmt { return ret }, nil, config.startSpanMatchers); ret != nil {
-				pass.ReportRangef(sv.stmt, "%s.End is not called on all paths, possible memory leak", sv.vr.Name())
-				pass.ReportRangef(ret, "return can be reached without calling %s.End", sv.vr.Name())
+		sv.start, sv.val = getSpanStart(fn, sv.call, sv.lib)
+		if sv.start == nil {
+			continue
+		}
+		if sv.lib.hasCheck(SpanNestingCheck) {
+			spans = append(spans, sv)
+		}
+
+		// Error returns outside the span variable's scope are unrelated to the span.
+		checkErr := func(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt {
+			if ret := getErrorReturn(pass, ret, res); ret != nil && (sv.vr.Parent() == nil || sv.vr.Parent().Contains(ret.Pos())) {
+				return ret
 			}
+			return nil
 		}
 
-		if config.setStatusEnabled {
-			// Check if there's no SetStatus to the span setting an error.
-			if ret := getMissingSpanCalls(pass, g, sv, selNameSetStatus, getErrorReturn, config.ignoreChecksSignatures, config.startSpanMatchers); ret != nil {
-				pass.ReportRangef(sv.stmt, "%s.SetStatus is not called on all paths", sv.vr.Name())
-				pass.ReportRangef(ret, "return can be reached without calling %s.SetStatus", sv.vr.Name())
+		missingEnd := false
+		if config.endCheckEnabled && sv.lib.hasCheck(EndCheck) {
+			// Check if there's no End to the span.
+			if ret := getMissingSpanCalls(pass, info, fn, sv, sv.lib.EndMethod, func(_ *analysis.Pass, ret *ast.ReturnStmt, _ *ssa.Return) *ast.ReturnStmt { return ret }, nil); ret != nil {
+				missingEnd = true
+				pass.Report(analysis.Diagnostic{
+					Pos:            sv.stmt.Pos(),
+					End:            sv.stmt.End(),
+					Message:        fmt.Sprintf("%s.%s is not called on all paths, possible memory leak", sv.vr.Name(), sv.lib.EndMethod),
+					SuggestedFixes: getEndFixes(pass, node, sv),
+				})
+				pass.ReportRangef(ret, "return can be reached without calling %s.%s", sv.vr.Name(), sv.lib.EndMethod)
 			}
 		}
 
-		if config.recordErrorEnabled && sv.spanType == spanOpenTelemetry { // RecordError only exists in OpenTelemetry
+		// Spans that are ended and marked as failed by the same call, like seg.Close(err) in
+		// AWS X-Ray, are only reported once if the call is missing.
+		if config.setStatusEnabled && sv.lib.hasCheck(SetStatusCheck) && !(missingEnd && sv.lib.SetStatusMethod == sv.lib.EndMethod) {
+			checkSetStatus(pass, info, fn, sv, checkErr, config.ignoreChecksSignatures)
+		}
+
+		if config.recordErrorEnabled && sv.lib.hasCheck(RecordErrorCheck) {
 			// Check if there's no RecordError to the span setting an error.
-			if ret := getMissingSpanCalls(pass, g, sv, selNameRecordError, getErrorReturn, config.ignoreChecksSignatures, config.startSpanMatchers); ret != nil {
-				pass.ReportRangef(sv.stmt, "%s.RecordError is not called on all paths", sv.vr.Name())
-				pass.ReportRangef(ret, "return can be reached without calling %s.RecordError", sv.vr.Name())
+			if ret := getMissingSpanCalls(pass, info, fn, sv, sv.lib.RecordErrorMethod, checkErr, config.ignoreChecksSignatures); ret != nil {
+				pass.ReportRangef(sv.stmt, "%s.%s is not called on all paths", sv.vr.Name(), sv.lib.RecordErrorMethod)
+				pass.Report(analysis.Diagnostic{
+					Pos:            ret.Pos(),
+					End:            ret.End(),
+					Message:        fmt.Sprintf("return can be reached without calling %s.%s", sv.vr.Name(), sv.lib.RecordErrorMethod),
+					SuggestedFixes: getRecordErrorFixes(pass, sv, ret),
+				})
 			}
 		}
+
+		if config.statusConsistencyEnabled && sv.lib.hasCheck(StatusConsistencyCheck) {
+			checkStatusConsistency(pass, info, fn, sv)
+		}
+
+		if config.recordErrorMatchEnabled && sv.lib.hasCheck(RecordErrorMatchCheck) {
+			checkRecordErrorMatch(pass, info, fn, sv)
+		}
+
+		if config.loopDeferEndEnabled && sv.lib.hasCheck(LoopDeferEndCheck) {
+			checkLoopDeferEnd(pass, info, fn, sv)
+		}
+
+		if config.goroutineEscapeEnabled && sv.lib.hasCheck(GoroutineEscapeCheck) {
+			checkGoroutineEscape(pass, info, fn, sv)
+		}
+
+		if config.useAfterEndEnabled && sv.lib.hasCheck(UseAfterEndCheck) {
+			checkUseAfterEnd(pass, info, fn, sv)
+		}
+
+		if config.doubleEndEnabled && sv.lib.hasCheck(DoubleEndCheck) {
+			checkDoubleEnd(pass, info, fn, sv)
+		}
+
+		if config.contextPropagationEnabled && sv.lib.hasCheck(ContextPropagationCheck) {
+			checkContextPropagation(pass, info, fn, sv)
+		}
+	}
+
+	if config.spanNestingEnabled {
+		checkSpanNesting(pass, info, fn, spans)
 	}
 }
 
-// isSpanStart reports whether n is tracer.Start()
-func isSpanStart(info *types.Info, n ast.Node, startSpanMatchers []spanStartMatcher) (spanType, bool) {
-	sel, ok := n.(*ast.SelectorExpr)
-	if !ok {
-		return spanUnset, false
+// isSpanStart reports whether n is tracer.Start(), or a function that wraps it, and
+// returns the library of the span.
+func isSpanStart(pass *analysis.Pass, n ast.Node, config *Config) (*SpanLibrary, bool) {
+	var obj types.Object
+	switch n := n.(type) {
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.ObjectOf(n.Sel)
+	case *ast.Ident:
+		// Only functions in the same package are called by identifier.
+		fn, ok := pass.TypesInfo.Uses[n].(*types.Func)
+		if !ok {
+			return nil, false
+		}
+		obj = fn
+	default:
+		return nil, false
 	}
 
-	fnSig := info.ObjectOf(sel.Sel).String()
+	return matchSpanStart(pass, obj, config)
+}
+
+// isSpanStartCall reports whether call is tracer.Start(), or a function that wraps it, and
+// returns the library of the span.
+func isSpanStartCall(pass *analysis.Pass, call *ssa.CallCommon, config *Config) (*SpanLibrary, bool) {
+	var obj types.Object
+	if call.IsInvoke() {
+		obj = call.Method
+	} else if fn := call.StaticCallee(); fn != nil && fn.Object() != nil {
+		obj = fn.Object()
+	} else {
+		return nil, false
+	}
+
+	return matchSpanStart(pass, obj, config)
+}
+
+// matchSpanStart reports whether the function obj is a span start function.
+func matchSpanStart(pass *analysis.Pass, obj types.Object, config *Config) (*SpanLibrary, bool) {
+	fnSig := obj.String()
 
 	// Check if the function is a span start function.
-	for _, matcher := range startSpanMatchers {
+	for _, matcher := range config.startSpanMatchers {
 		if matcher.signature.MatchString(fnSig) {
-			return matcher.spanType, true
+			return matcher.lib, true
+		}
+	}
+
+	// Check if the function returns a span from a span start function.
+	if fn, ok := obj.(*types.Func); ok {
+		var fact spanStartFact
+		if pass.ImportObjectFact(fn, &fact) {
+			if lib := config.getSpanLibrary(fact.Library); lib != nil {
+				return lib, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// getInsertPos returns the position after the span defining statement at the top
+// of the stack, if that statement is in a statement list.
+func getInsertPos(stack []ast.Node) token.Pos {
+	var stmt ast.Node
+	var parent ast.Node
+	switch stack[len(stack)-1].(type) {
+	case *ast.AssignStmt:
+		if len(stack) < 2 {
+			return token.NoPos
+		}
+		stmt, parent = stack[len(stack)-1], stack[len(stack)-2]
+	case *ast.ValueSpec:
+		// var ctx, span = otel.Tracer("app").Start(...) is a ValueSpec
+		// within a GenDecl within a DeclStmt.
+		if len(stack) < 4 {
+			return token.NoPos
 		}
+		stmt, parent = stack[len(stack)-2], stack[len(stack)-4]
+	default:
+		return token.NoPos
 	}
 
-	return 0, false
+	switch parent.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return stmt.End()
+	}
+
+	return token.NoPos
 }
 
 func isCall(n ast.Node) bool {
@@ -237,253 +392,176 @@ func isCall(n ast.Node) bool {
 	return ok
 }
 
-func getID(node ast.Node) *ast.Ident {
+// getID returns the identifier the span of lib returned by call is assigned to in node.
+func getID(info *types.Info, node ast.Node, call ast.Node, lib *SpanLibrary) *ast.Ident {
 	switch stmt := node.(type) {
 	case *ast.ValueSpec:
-		if len(stmt.Names) > 1 {
-			return stmt.Names[1]
-		} else if len(stmt.Names) == 1 {
-			return stmt.Names[0]
+		if i := getSpanIndex(info, stmt.Values, call, lib); i < len(stmt.Names) {
+			return stmt.Names[i]
 		}
 	case *ast.AssignStmt:
-		if len(stmt.Lhs) > 1 {
-			id, _ := stmt.Lhs[1].(*ast.Ident)
-			return id
-		} else if len(stmt.Lhs) == 1 {
-			id, _ := stmt.Lhs[0].(*ast.Ident)
+		if i := getSpanIndex(info, stmt.Rhs, call, lib); i < len(stmt.Lhs) {
+			id, _ := stmt.Lhs[i].(*ast.Ident)
 			return id
 		}
 	}
 	return nil
 }
 
-// getMissingSpanCalls finds a path through the CFG, from stmt (which defines
-// the 'span' variable v) to a return statement, that doesn't call the passed selector on the span.
-func getMissingSpanCalls(
-	pass *analysis.Pass,
-	g *cfg.CFG,
-	sv spanVar,
-	selName string,
-	checkErr func(pass *analysis.Pass, ret *ast.ReturnStmt) *ast.ReturnStmt,
-	ignoreCheckSig *regexp.Regexp,
-	spanStartMatchers []spanStartMatcher,
-) *ast.ReturnStmt {
-	// blockUses computes "uses" for each block, caching the result.
-	memo := make(map[*cfg.Block]bool)
-	blockUses := func(pass *analysis.Pass, b *cfg.Block) bool {
-		res, ok := memo[b]
-		if !ok {
-			res = usesCall(pass, b.Nodes, sv, selName, ignoreCheckSig, spanStartMatchers, 0)
-			memo[b] = res
-		}
-		return res
-	}
-
-	// Find the var's defining block in the CFG,
-	// plus the rest of the statements of that block.
-	var defBlock *cfg.Block
-	var rest []ast.Node
-outer:
-	for _, b := range g.Blocks {
-		for i, n := range b.Nodes {
-			if n == sv.stmt {
-				defBlock = b
-				rest = b.Nodes[i+1:]
-				break outer
+// getSpanIndex returns the index of the span returned by call among the
+// assigned values.
+func getSpanIndex(info *types.Info, values []ast.Expr, call ast.Node, lib *SpanLibrary) int {
+	if len(values) > 1 {
+		for i, v := range values {
+			if v == call {
+				return i
 			}
 		}
 	}
 
-	// Is the call "used" in the remainder of its defining block?
-	if usesCall(pass, rest, sv, selName, ignoreCheckSig, spanStartMatchers, 0) {
-		return nil
+	expr, ok := call.(ast.Expr)
+	if !ok {
+		return 0
 	}
 
-	// Does the defining block return without making the call?
-	if ret := defBlock.Return(); ret != nil {
-		return checkErr(pass, ret)
+	tuple, ok := info.TypeOf(expr).(*types.Tuple)
+	if !ok {
+		return 0
 	}
 
-	// Search the CFG depth-first for a path, from defblock to a
-	// return block, in which v is never "used".
-	seen := make(map[*cfg.Block]bool)
-	var search func(blocks []*cfg.Block) *ast.ReturnStmt
-	search = func(blocks []*cfg.Block) *ast.ReturnStmt {
-		for _, b := range blocks {
-			if seen[b] {
-				continue
-			}
-			seen[b] = true
-
-			// Skip successors that are not nested within this current block.
-			if _, ok := nestedBlockTypes[b.Kind]; !ok {
-				continue
-			}
-
-			// Prune the search if the block uses v.
-			if blockUses(pass, b) {
-				continue
-			}
-
-			// Found path to return statement?
-			if ret := getErrorReturn(pass, b.Return()); ret != nil {
-				return ret // found
-			}
+	return getSpanResultIndex(tuple, lib)
+}
 
-			// Recur
-			if ret := getErrorReturn(pass, search(b.Succs)); ret != nil {
-				return ret
-			}
+// getSpanResultIndex returns the index of the span among the results of a span
+// start: the first one with a span type of lib, falling back to the second result.
+func getSpanResultIndex(tuple *types.Tuple, lib *SpanLibrary) int {
+	for i := 0; i < tuple.Len(); i++ {
+		if lib.isSpanType(tuple.At(i).Type()) {
+			return i
 		}
-		return nil
 	}
 
-	return search(defBlock.Succs)
-}
-
-var nestedBlockTypes = map[cfg.BlockKind]struct{}{
-	cfg.KindBody:            {},
-	cfg.KindForBody:         {},
-	cfg.KindForLoop:         {},
-	cfg.KindIfElse:          {},
-	cfg.KindIfThen:          {},
-	cfg.KindLabel:           {},
-	cfg.KindRangeBody:       {},
-	cfg.KindRangeLoop:       {},
-	cfg.KindSelectCaseBody:  {},
-	cfg.KindSelectAfterCase: {},
-	cfg.KindSwitchCaseBody:  {},
-	cfg.KindSwitchNextCase:  {},
+	return 1
 }
 
-// usesCall reports whether stmts contain a use of the selName call on variable v.
-func usesCall(
+// getMissingSpanCalls finds a path through the function, from the span's start
+// (or the function's entry, for span parameters) to a return statement, that
+// doesn't call the passed selector on the span.
+func getMissingSpanCalls(
 	pass *analysis.Pass,
-	stmts []ast.Node,
+	info *ssaInfo,
+	fn *ssa.Function,
 	sv spanVar,
 	selName string,
+	checkErr func(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt,
 	ignoreCheckSig *regexp.Regexp,
-	startSpanMatchers []spanStartMatcher,
-	depth int,
-) bool {
-	if depth > 1 { // for perf reasons, do not dive too deep thru func literals, just two levels deep.
-		return false
+) *ast.ReturnStmt {
+	s := &callSearch{
+		pass:           pass,
+		info:           info,
+		aliases:        newValueAliases(fn, sv.val),
+		selName:        selName,
+		ignoreCheckSig: ignoreCheckSig,
+		lib:            sv.lib,
+		anyPath:        selName != sv.lib.EndMethod,
 	}
 
-	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
-
-	found, reAssigned := false, false
-	for _, subStmt := range stmts {
-		stack := []ast.Node{}
-		ast.Inspect(subStmt, func(n ast.Node) bool {
-			switch n := n.(type) {
-			case *ast.FuncLit:
-				if len(stack) > 0 {
-					g := cfgs.FuncLit(n)
-					if g != nil && len(g.Blocks) > 0 {
-						return usesCall(pass, g.Blocks[0].Nodes, sv, selName, ignoreCheckSig, startSpanMatchers, depth+1)
-					}
+	return s.findMissingReturn(fn, sv.start, checkErr)
+}
 
-					return false
-				}
-			case *ast.CallExpr:
-				if ident, ok := n.Fun.(*ast.Ident); ok {
-					fnSig := pass.TypesInfo.ObjectOf(ident).String()
-					if ignoreCheckSig != nil && ignoreCheckSig.MatchString(fnSig) {
-						found = true
-						return false
-					}
-				}
-			case *ast.DeferStmt:
-				if n.Call == nil {
-					break
-				}
+// findMissingReturn finds a return statement, reached from the instruction after from,
+// on which the call is never made and for which checkErr returns the statement.
+func (s *callSearch) findMissingReturn(
+	fn *ssa.Function,
+	from ssa.Instruction,
+	checkErr func(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt,
+) *ast.ReturnStmt {
+	returns := getReturnStmts(fn)
+	var missing *ast.ReturnStmt
+	s.findMissingCall(fn, from, 0, func(ret *ssa.Return) bool {
+		stmt, ok := returns[ret.Pos()]
+		if !ok {
+			stmt = &ast.ReturnStmt{Return: ret.Pos()}
+		}
 
-				f, ok := n.Call.Fun.(*ast.FuncLit)
-				if !ok {
-					break
-				}
+		missing = checkErr(s.pass, stmt, ret)
+		return missing != nil
+	})
 
-				if g := cfgs.FuncLit(f); g != nil && len(g.Blocks) > 0 {
-					if selName == selNameEnd {
-						// Check if all returning blocks call end.
-						for _, b := range g.Blocks {
-							if b.Return() != nil && !usesCall(
-								pass,
-								b.Nodes,
-								sv,
-								selName,
-								ignoreCheckSig,
-								startSpanMatchers,
-								depth+1,
-							) {
-								return false
-							}
-						}
-
-						found = true
-						return false
-					}
+	return missing
+}
 
-					for _, b := range g.Blocks {
-						if usesCall(
-							pass,
-							b.Nodes,
-							sv,
-							selName,
-							ignoreCheckSig,
-							startSpanMatchers,
-							depth+1,
-						) {
-							found = true
-							return false
-						}
+// usesCall reports whether instrs contain a use of the selName call on the span.
+func (s *callSearch) usesCall(instrs []ssa.Instruction, depth int) bool {
+	if depth > 1 { // for perf reasons, do not dive too deep thru func literals, just two levels deep.
+		return false
+	}
+
+	for _, instr := range instrs {
+		switch instr := instr.(type) {
+		case *ssa.MakeClosure:
+			fn, aliases := s.aliases.forClosure(instr)
+			if fn == nil {
+				continue
+			}
+
+			closure := *s
+			closure.aliases = aliases
+
+			// Deferred functions must end the span on all paths, but may set
+			// errors on some paths only, e.g. if err != nil.
+			if isDeferred(instr) && s.anyPath {
+				for _, b := range fn.Blocks {
+					if closure.usesCall(b.Instrs, depth+1) {
+						return true
 					}
 				}
-			case nil:
-				if len(stack) > 0 {
-					stack = stack[:len(stack)-1] // pop
-					return true
-				}
-				return false
+				continue
 			}
-			stack = append(stack, n) // push
-
-			// Check whether the span was assigned over top of its old value.
-			_, isStart := isSpanStart(pass.TypesInfo, n, startSpanMatchers)
-			if isStart {
-				if id := getID(stack[len(stack)-3]); id != nil && id.Obj.Decl == sv.id.Obj.Decl {
-					reAssigned = true
-					return false
-				}
+
+			if closure.findMissingCall(fn, nil, depth+1, func(*ssa.Return) bool { return true }) == nil {
+				return true
+			}
+		case *ssa.Store:
+			// Field (Status) hit.
+			if s.setsField(instr) {
+				return true
 			}
+		case ssa.CallInstruction:
+			call := instr.Common()
 
-			if n, ok := n.(*ast.SelectorExpr); ok {
-				// Selector (End, SetStatus, RecordError) hit.
-				if n.Sel.Name == selName {
-					id, ok := n.X.(*ast.Ident)
-					found = ok && id.Obj != nil && id.Obj.Decl == sv.id.Obj.Decl
-				}
+			// Selector (End, SetStatus, RecordError) hit.
+			matches, known := s.matchesCall(instr)
+			if matches {
+				return true
+			}
 
-				// Check if an ignore signature matches.
-				fnSig := pass.TypesInfo.ObjectOf(n.Sel).String()
-				if ignoreCheckSig != nil && ignoreCheckSig.MatchString(fnSig) {
-					found = true
-				}
+			// Check if an ignore signature matches.
+			if s.ignoreCheckSig != nil && s.ignoreCheckSig.MatchString(getCalleeSignature(call)) {
+				return true
 			}
 
-			return !found
-		})
+			// Check if the span is passed to a function that makes the call.
+			if !known && callsSpanParam(s.pass, call, s.aliases, s.selName) {
+				return true
+			}
+		}
 	}
 
-	return found && !reAssigned
+	return false
 }
 
-func getErrorReturn(pass *analysis.Pass, ret *ast.ReturnStmt) *ast.ReturnStmt {
+// getErrorReturn returns ret if it returns an error. res is the SSA return of ret,
+// used to find the values of named results returned by bare returns.
+func getErrorReturn(pass *analysis.Pass, ret *ast.ReturnStmt, res *ssa.Return) *ast.ReturnStmt {
 	if ret == nil {
 		return nil
 	}
 
+	if len(ret.Results) == 0 && res != nil && mayReturnError(res) {
+		return ret
+	}
+
 	for _, r := range ret.Results {
 		if isErrorType(pass.TypesInfo.TypeOf(r)) {
 			return ret
@@ -501,6 +579,83 @@ func getErrorReturn(pass *analysis.Pass, ret *ast.ReturnStmt) *ast.ReturnStmt {
 	return nil
 }
 
+// mayReturnError reports whether an error result of ret may be non-nil.
+func mayReturnError(ret *ssa.Return) bool {
+	results := ret.Parent().Signature.Results()
+	for i, v := range ret.Results {
+		if i < results.Len() && isErrorType(results.At(i).Type()) && mayBeNonNil(v, make(map[ssa.Value]bool)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mayBeNonNil reports whether v may be a non-nil value. Named results that are
+// captured by closures live in memory, so the values stored to them are checked.
+func mayBeNonNil(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return false
+	}
+	seen[v] = true
+
+	switch v := v.(type) {
+	case *ssa.Const:
+		return !v.IsNil()
+	case *ssa.Phi:
+		for _, edge := range v.Edges {
+			if mayBeNonNil(edge, seen) {
+				return true
+			}
+		}
+		return false
+	case *ssa.UnOp:
+		if v.Op == token.MUL {
+			return mayStoreNonNil(v.X, seen)
+		}
+	}
+
+	return true
+}
+
+// mayStoreNonNil reports whether a non-nil value may be stored to addr, a local
+// variable or a closure's free variable.
+func mayStoreNonNil(addr ssa.Value, seen map[ssa.Value]bool) bool {
+	switch addr.(type) {
+	case *ssa.Alloc, *ssa.FreeVar:
+	default:
+		return true
+	}
+
+	for _, ref := range *addr.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Addr != addr || mayBeNonNil(ref.Val, seen) {
+				return true // the address escapes, or a non-nil value is stored
+			}
+		case *ssa.UnOp:
+			if ref.Op != token.MUL {
+				return true
+			}
+		case *ssa.MakeClosure:
+			fn, ok := ref.Fn.(*ssa.Function)
+			if !ok {
+				return true
+			}
+			for i, binding := range ref.Bindings {
+				if binding == addr && i < len(fn.FreeVars) && mayStoreNonNil(fn.FreeVars[i], seen) {
+					return true
+				}
+			}
+		case *ssa.DebugRef:
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
 // errorsByArg returns a slice s such that
 // len(s) == number of return types of call
 // s[i] == true iff return type at position i from left is an error type
@@ -508,30 +663,31 @@ func getErrorReturn(pass *analysis.Pass, ret *ast.ReturnStmt) *ast.ReturnStmt {
 // copied from https://github.com/kisielk/errcheck/blob/master/errcheck/errcheck.go
 func errorsByArg(pass *analysis.Pass, call *ast.CallExpr) []bool {
 	switch t := pass.TypesInfo.Types[call].Type.(type) {
-	case *types.Named:
-		// Single return
-		return []bool{isErrorType(t)}
-	case *types.Pointer:
-		// Single return via pointer
-		return []bool{isErrorType(t)}
 	case *types.Tuple:
 		// Multiple returns
 		s := make([]bool, t.Len())
 		for i := 0; i < t.Len(); i++ {
-			switch et := t.At(i).Type().(type) {
-			case *types.Named:
-				// Single return
-				s[i] = isErrorType(et)
-			case *types.Pointer:
-				// Single return via pointer
-				s[i] = isErrorType(et)
-			default:
-				s[i] = false
-			}
+			s[i] = isErrorResult(t.At(i).Type())
 		}
 		return s
+	case nil:
+		return []bool{false}
+	default:
+		// Single return
+		return []bool{isErrorResult(t)}
+	}
+}
+
+// isErrorResult reports whether t, the type of a result, is an error. Only types
+// that can implement error are checked: named types, pointers, interfaces, and type
+// parameters, seen through aliases.
+func isErrorResult(t types.Type) bool {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named, *types.Pointer, *types.Interface, *types.TypeParam:
+		return isErrorType(t)
+	default:
+		return false
 	}
-	return []bool{false}
 }
 
 func isErrorType(t types.Type) bool {
@@ -15,6 +15,14 @@ func Test(t *testing.T) {
 
 	for dir, configFactory := range map[string]configFactory{
 		"base": spancheck.NewDefaultConfig,
+		"contextpropagation": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.ContextPropagationCheck.String(),
+			}
+
+			return cfg
+		},
 		"disableerrorchecks": func() *spancheck.Config {
 			cfg := spancheck.NewDefaultConfig()
 			cfg.EnabledChecks = []string{
@@ -26,6 +34,14 @@ func Test(t *testing.T) {
 
 			return cfg
 		},
+		"doubleend": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.DoubleEndCheck.String(),
+			}
+
+			return cfg
+		},
 		"enableall": func() *spancheck.Config {
 			cfg := spancheck.NewDefaultConfig()
 			cfg.EnabledChecks = []string{
@@ -38,6 +54,123 @@ func Test(t *testing.T) {
 				"enableall.testStartTrace:opencensus",
 			)
 
+			return cfg
+		},
+		"foreignspanend": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.ForeignSpanEndCheck.String(),
+			}
+
+			return cfg
+		},
+		"goroutineescape": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.GoroutineEscapeCheck.String(),
+			}
+
+			return cfg
+		},
+		"interprocedural": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.RecordErrorCheck.String(),
+				spancheck.SetStatusCheck.String(),
+			}
+
+			return cfg
+		},
+		"loopdeferend": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.LoopDeferEndCheck.String(),
+			}
+
+			return cfg
+		},
+		"recorderrormatch": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.RecordErrorMatchCheck.String(),
+			}
+
+			return cfg
+		},
+		"spanlibrary": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.SetStatusCheck.String(),
+				spancheck.RecordErrorCheck.String(),
+				spancheck.UseAfterEndCheck.String(),
+				spancheck.BackgroundContextCheck.String(),
+				spancheck.SpanNameCheck.String(),
+				spancheck.ForeignSpanEndCheck.String(),
+			}
+			cfg.SpanLibraries = append(cfg.SpanLibraries, spancheck.SpanLibrary{
+				Name:                  "mytrace",
+				StartSpanSignatures:   []string{`spanlibrary/mytrace.Start\b`},
+				SpanTypes:             []string{"*github.com/jjti/go-spancheck/testdata/spanlibrary/mytrace.Span"},
+				EndMethod:             "Finish",
+				RecordErrorMethod:     "Fail",
+				ReadOnlyMethods:       []string{"ID"},
+				ForeignSpanSignatures: []string{`spanlibrary/mytrace.FromContext\b`},
+				Checks: []string{
+					spancheck.EndCheck.String(),
+					spancheck.RecordErrorCheck.String(),
+					spancheck.UseAfterEndCheck.String(),
+					spancheck.ForeignSpanEndCheck.String(),
+				},
+			})
+			cfg.StartSpanMatchersSlice = append(cfg.StartSpanMatchersSlice,
+				"spanlibrary.startTrace:mytrace",
+			)
+
+			return cfg
+		},
+		"spanname": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.SpanNameCheck.String(),
+			}
+			cfg.SpanNamePattern = `^[a-z]+(\.[a-z_]+)*$`
+
+			return cfg
+		},
+		"spannesting": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.SpanNestingCheck.String(),
+			}
+
+			return cfg
+		},
+		"statusconsistency": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.StatusConsistencyCheck.String(),
+			}
+
+			return cfg
+		},
+		"useafterend": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.UseAfterEndCheck.String(),
+			}
+
+			return cfg
+		},
+		"xray": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.SetStatusCheck.String(),
+				spancheck.ForeignSpanEndCheck.String(),
+			}
+
 			return cfg
 		},
 	} {
@@ -47,3 +180,87 @@ func Test(t *testing.T) {
 		})
 	}
 }
+
+func TestSuggestedFixes(t *testing.T) {
+	t.Parallel()
+
+	type configFactory func() *spancheck.Config
+
+	for dir, configFactory := range map[string]configFactory{
+		"backgroundcontext": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.BackgroundContextCheck.String(),
+			}
+
+			return cfg
+		},
+		"datadog": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.SetStatusCheck.String(),
+				spancheck.RecordErrorCheck.String(),
+				spancheck.UseAfterEndCheck.String(),
+				spancheck.StatusConsistencyCheck.String(),
+			}
+
+			return cfg
+		},
+		"opentracing": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.SetStatusCheck.String(),
+				spancheck.RecordErrorCheck.String(),
+				spancheck.UseAfterEndCheck.String(),
+				spancheck.StatusConsistencyCheck.String(),
+			}
+
+			return cfg
+		},
+		"sentry": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.SetStatusCheck.String(),
+				spancheck.UseAfterEndCheck.String(),
+				spancheck.ForeignSpanEndCheck.String(),
+			}
+
+			return cfg
+		},
+		"suggestedfixes": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.EndCheck.String(),
+				spancheck.RecordErrorCheck.String(),
+				spancheck.SetStatusCheck.String(),
+			}
+
+			return cfg
+		},
+		"tracerhoist": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.TracerUsageCheck.String(),
+			}
+
+			return cfg
+		},
+		"tracerusage": func() *spancheck.Config {
+			cfg := spancheck.NewDefaultConfig()
+			cfg.EnabledChecks = []string{
+				spancheck.TracerUsageCheck.String(),
+			}
+			cfg.TracerNameImportPath = true
+
+			return cfg
+		},
+	} {
+		dir := dir
+		t.Run(dir, func(t *testing.T) {
+			analysistest.RunWithSuggestedFixes(t, "testdata/"+dir, spancheck.NewAnalyzerWithConfig(configFactory()))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkSpanName reports span starts whose name is not a constant, or does not match
+// pattern if it is set. Names built from variables, like IDs, have a high cardinality.
+func checkSpanName(pass *analysis.Pass, call *ast.CallExpr, pattern *regexp.Regexp) {
+	name := getSpanNameArg(pass.TypesInfo, call)
+	if name == nil {
+		return
+	}
+
+	tv := pass.TypesInfo.Types[name]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		pass.ReportRangef(name, "span name is not a constant, span names should have a low cardinality")
+		return
+	}
+
+	if val := constant.StringVal(tv.Value); pattern != nil && !pattern.MatchString(val) {
+		pass.ReportRangef(name, "span name %q does not match %s", val, pattern)
+	}
+}
+
+// getSpanNameArg returns the span name passed to call, a span start: its first string argument.
+func getSpanNameArg(info *types.Info, call *ast.CallExpr) ast.Expr {
+	for _, arg := range call.Args {
+		t := info.TypeOf(arg)
+		if t == nil {
+			continue
+		}
+
+		if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+			return arg
+		}
+	}
+
+	return nil
+}
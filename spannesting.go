@@ -0,0 +1,122 @@
+package spancheck
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkSpanNesting reports calls that end a span before the spans started from its
+// context, its children, are ended. Trace backends show the children outliving their parent.
+func checkSpanNesting(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, spans []spanVar) {
+	for _, parent := range spans {
+		ctx := getContextValue(parent.start)
+		if ctx == nil {
+			continue
+		}
+		ctxs := newValueAliases(fn, ctx)
+
+		for _, child := range spans {
+			start, ok := child.start.(*ssa.Call)
+			if !ok || child.start == parent.start {
+				continue
+			}
+
+			// Children are started from the context returned with the parent.
+			if ctxArgs := getContextArgs(start.Common()); len(ctxArgs) == 0 || !ctxs.has(ctxArgs[0]) {
+				continue
+			}
+
+			checkEndOrder(pass, info, fn, parent, child)
+		}
+	}
+}
+
+// checkEndOrder reports calls that end parent, after which child can still be ended.
+func checkEndOrder(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, parent, child spanVar) {
+	ps := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, parent.val),
+		selName: parent.lib.EndMethod,
+	}
+	cs := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, child.val),
+		selName: child.lib.EndMethod,
+	}
+
+	// Paths end where either span is started anew.
+	stop := func(instr ssa.Instruction) bool {
+		return instr == parent.start || instr == child.start
+	}
+
+	var childDefers []*ssa.Defer
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if d, ok := instr.(*ssa.Defer); ok && cs.endsSpan(d) {
+				childDefers = append(childDefers, d)
+			}
+		}
+	}
+
+	calls := info.getCallExprs(fn)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			end, ok := instr.(ssa.CallInstruction)
+			if !ok || !ps.endsSpan(end) {
+				continue
+			}
+
+			// The child is ended later, or by a call deferred on the path to this one. Deferred
+			// calls run in reverse order, so a deferred parent's runs first if it is deferred
+			// after the child's, and a parent ended directly is ended before the function returns.
+			before := false
+			if _, ok := end.(*ssa.Call); ok {
+				before = cs.findCall(end, stop, cs.endsSpan) != nil
+			}
+			for _, d := range childDefers {
+				if before {
+					break
+				}
+				before = ps.findCall(d, stop, func(call ssa.CallInstruction) bool { return call == end }) != nil
+			}
+			if !before {
+				continue
+			}
+
+			if call, ok := calls[end.Common().Pos()]; ok {
+				pass.ReportRangef(call, "%s.%s is called before %s.%s, child spans should end before their parent", parent.vr.Name(), ps.selName, child.vr.Name(), cs.selName)
+			}
+		}
+	}
+}
+
+// getContextValue returns the context returned by start, a span start call, if it is used.
+func getContextValue(start ssa.Instruction) ssa.Value {
+	call, ok := start.(*ssa.Call)
+	if !ok {
+		return nil
+	}
+
+	tuple, ok := call.Type().(*types.Tuple)
+	if !ok {
+		return nil
+	}
+
+	for i := 0; i < tuple.Len(); i++ {
+		if !isContextType(tuple.At(i).Type()) {
+			continue
+		}
+
+		for _, ref := range *call.Referrers() {
+			if extract, ok := ref.(*ssa.Extract); ok && extract.Index == i {
+				return extract
+			}
+		}
+	}
+
+	return nil
+}
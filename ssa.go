@@ -0,0 +1,770 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxCanonDepth limits how many loads and free variables canon looks through.
+const maxCanonDepth = 8
+
+// ssaInfo is the SSA form of the package's functions.
+type ssaInfo struct {
+	// funcs maps function declarations and literals to their SSA functions.
+	funcs map[ast.Node]*ssa.Function
+
+	// noReturn holds the positions (left parentheses) of calls that never return,
+	// like log.Fatal, as computed by the ctrlflow analyzer.
+	noReturn map[token.Pos]bool
+
+	// calls maps SSA functions to their calls, see getCallExprs.
+	calls map[*ssa.Function]map[token.Pos]*ast.CallExpr
+}
+
+// newSSAInfo builds the SSA form of the package. It returns nil if the package can't
+// have spans, because it neither is nor imports a package of the span libraries, like
+// the standard library. Facts make the analyzer run on every dependency, so SSA is only
+// built where facts can be found.
+func newSSAInfo(pass *analysis.Pass, config *Config) *ssaInfo {
+	if !importsSpanPackage(pass.Pkg, config.spanPackages, make(map[*types.Package]bool)) {
+		return nil
+	}
+
+	info := &ssaInfo{
+		funcs:    make(map[ast.Node]*ssa.Function),
+		noReturn: make(map[token.Pos]bool),
+		calls:    make(map[*ssa.Function]map[token.Pos]*ast.CallExpr),
+	}
+
+	fns := buildSSA(pass)
+
+	for len(fns) > 0 {
+		fn := fns[0]
+		fns = fns[1:]
+
+		if syntax := fn.Syntax(); syntax != nil {
+			if _, ok := info.funcs[syntax]; ok {
+				continue
+			}
+			info.funcs[syntax] = fn
+		}
+
+		fns = append(fns, fn.AnonFuncs...)
+	}
+
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+	for syntax := range info.funcs {
+		var g *cfg.CFG
+		switch syntax := syntax.(type) {
+		case *ast.FuncDecl:
+			g = cfgs.FuncDecl(syntax)
+		case *ast.FuncLit:
+			g = cfgs.FuncLit(syntax)
+		}
+		if g == nil {
+			continue
+		}
+
+		// Calls that never return end their block, which has no successors.
+		for _, b := range g.Blocks {
+			if !b.Live || len(b.Succs) > 0 || len(b.Nodes) == 0 {
+				continue
+			}
+
+			if stmt, ok := b.Nodes[len(b.Nodes)-1].(*ast.ExprStmt); ok {
+				if call, ok := stmt.X.(*ast.CallExpr); ok {
+					info.noReturn[call.Lparen] = true
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+// importsSpanPackage reports whether pkg is, or imports, one of the span packages.
+func importsSpanPackage(pkg *types.Package, spanPackages map[string]bool, seen map[*types.Package]bool) bool {
+	if seen[pkg] {
+		return false
+	}
+	seen[pkg] = true
+
+	if spanPackages[pkg.Path()] {
+		return true
+	}
+
+	for _, imp := range pkg.Imports() {
+		if importsSpanPackage(imp, spanPackages, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildSSA builds the SSA form of the package, as buildssa.Analyzer does, and returns
+// the functions of its declarations. Function literals in package-level variables
+// belong to the package initializer, whose function is returned with them.
+func buildSSA(pass *analysis.Pass) []*ssa.Function {
+	prog := ssa.NewProgram(pass.Fset, 0)
+	for _, imp := range pass.Pkg.Imports() {
+		prog.CreatePackage(imp, nil, nil, true)
+	}
+
+	pkg := prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
+	pkg.Build()
+
+	var fns []*ssa.Function
+	for _, decl := range getFuncDecls(pass) {
+		if obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+			if fn := prog.FuncValue(obj); fn != nil {
+				fns = append(fns, fn)
+			}
+		}
+	}
+
+	if init := pkg.Func("init"); init != nil {
+		fns = append(fns, init.AnonFuncs...)
+	}
+
+	return fns
+}
+
+// getSpanStart returns the call that starts the span of lib, found at the position of
+// call in fn, and the span value it returns. The value is nil if it is unused.
+func getSpanStart(fn *ssa.Function, call *ast.CallExpr, lib *SpanLibrary) (ssa.Instruction, ssa.Value) {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if c, ok := instr.(*ssa.Call); ok && c.Pos() == call.Lparen {
+				return c, getSpanValue(c, lib)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// getSpanValue returns the span value returned by a call to a span start of lib.
+func getSpanValue(call *ssa.Call, lib *SpanLibrary) ssa.Value {
+	tuple, ok := call.Type().(*types.Tuple)
+	if !ok {
+		return call
+	}
+
+	i := getSpanResultIndex(tuple, lib)
+	for _, ref := range *call.Referrers() {
+		if extract, ok := ref.(*ssa.Extract); ok && extract.Index == i {
+			return extract
+		}
+	}
+
+	return nil
+}
+
+// getReturnStmts maps the positions of the return statements in fn to the statements.
+func getReturnStmts(fn *ssa.Function) map[token.Pos]*ast.ReturnStmt {
+	body := getFuncBody(fn)
+	returns := make(map[token.Pos]*ast.ReturnStmt)
+	if body == nil {
+		return returns
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // don't stray into nested functions
+		case *ast.ReturnStmt:
+			returns[n.Pos()] = n
+		}
+		return true
+	})
+
+	// Falling off the end of the function is an implicit return at the closing brace.
+	returns[token.NoPos] = &ast.ReturnStmt{Return: body.Rbrace}
+
+	return returns
+}
+
+// getCallExprs maps the positions (left parentheses) of the calls in fn to the calls.
+// The map is built once per function.
+func (info *ssaInfo) getCallExprs(fn *ssa.Function) map[token.Pos]*ast.CallExpr {
+	calls, ok := info.calls[fn]
+	if !ok {
+		calls = getCallExprs(fn)
+		info.calls[fn] = calls
+	}
+
+	return calls
+}
+
+// getCallExprs maps the positions (left parentheses) of the calls in fn to the calls.
+func getCallExprs(fn *ssa.Function) map[token.Pos]*ast.CallExpr {
+	calls := make(map[token.Pos]*ast.CallExpr)
+	body := getFuncBody(fn)
+	if body == nil {
+		return calls
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // don't stray into nested functions
+		case *ast.CallExpr:
+			calls[n.Lparen] = n
+		}
+		return true
+	})
+
+	return calls
+}
+
+func getFuncBody(fn *ssa.Function) *ast.BlockStmt {
+	switch syntax := fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		return syntax.Body
+	case *ast.FuncLit:
+		return syntax.Body
+	}
+
+	return nil
+}
+
+// valueAliases is the set of SSA values, and memory locations, that may hold
+// a value, like a span.
+//
+// It is flow-insensitive: a location holds the value if the value is stored to it
+// anywhere in the function.
+type valueAliases struct {
+	values map[ssa.Value]bool
+	locs   map[valueLoc]bool
+
+	// bindings maps the free variables of closures to the captured variables.
+	bindings map[*ssa.FreeVar]ssa.Value
+}
+
+// valueLoc is a memory location: a variable, or a field of a struct.
+type valueLoc struct {
+	base  ssa.Value
+	field int // -1 for the whole variable
+}
+
+func newValueAliases(fn *ssa.Function, vals ...ssa.Value) *valueAliases {
+	a := &valueAliases{
+		values:   make(map[ssa.Value]bool),
+		locs:     make(map[valueLoc]bool),
+		bindings: make(map[*ssa.FreeVar]ssa.Value),
+	}
+	for _, v := range vals {
+		if v != nil {
+			a.values[v] = true
+		}
+	}
+	a.compute(fn)
+
+	return a
+}
+
+// forClosure returns the aliases of the value within the function created by mc.
+func (a *valueAliases) forClosure(mc *ssa.MakeClosure) (*ssa.Function, *valueAliases) {
+	fn, ok := mc.Fn.(*ssa.Function)
+	if !ok {
+		return nil, nil
+	}
+
+	closure := &valueAliases{
+		values:   make(map[ssa.Value]bool, len(a.values)),
+		locs:     make(map[valueLoc]bool, len(a.locs)),
+		bindings: make(map[*ssa.FreeVar]ssa.Value, len(a.bindings)+len(mc.Bindings)),
+	}
+	for v := range a.values {
+		closure.values[v] = true
+	}
+	for l := range a.locs {
+		closure.locs[l] = true
+	}
+	for fv, v := range a.bindings {
+		closure.bindings[fv] = v
+	}
+	for i, v := range mc.Bindings {
+		if i < len(fn.FreeVars) {
+			closure.bindings[fn.FreeVars[i]] = v
+		}
+	}
+	closure.compute(fn)
+
+	return fn, closure
+}
+
+// has reports whether v may be the value.
+func (a *valueAliases) has(v ssa.Value) bool {
+	return v != nil && (a.values[v] || a.values[a.canon(v)])
+}
+
+// compute adds the values and locations the value flows to in fn, until none change.
+func (a *valueAliases) compute(fn *ssa.Function) {
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if a.flow(instr) {
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// flow adds the value or location the value flows to through instr, if any,
+// and reports whether it was added.
+func (a *valueAliases) flow(instr ssa.Instruction) bool {
+	switch instr := instr.(type) {
+	case *ssa.Store:
+		// *addr = span
+		if a.has(instr.Val) {
+			return a.addLoc(a.getLoc(instr.Addr))
+		}
+	case *ssa.UnOp:
+		// s := *addr
+		if instr.Op == token.MUL && a.locs[a.getLoc(instr.X)] {
+			return a.addValue(instr)
+		}
+	case *ssa.Field:
+		// s := w.span, where w is loaded from memory
+		if load, ok := instr.X.(*ssa.UnOp); ok && load.Op == token.MUL && a.locs[valueLoc{base: a.canon(load.X), field: instr.Field}] {
+			return a.addValue(instr)
+		}
+	case *ssa.Phi:
+		for _, edge := range instr.Edges {
+			if a.has(edge) {
+				return a.addValue(instr)
+			}
+		}
+	case *ssa.ChangeType:
+		if a.has(instr.X) {
+			return a.addValue(instr)
+		}
+	case *ssa.ChangeInterface:
+		if a.has(instr.X) {
+			return a.addValue(instr)
+		}
+	case *ssa.MakeInterface:
+		if a.has(instr.X) {
+			return a.addValue(instr)
+		}
+	case *ssa.TypeAssert:
+		if a.has(instr.X) && !instr.CommaOk {
+			return a.addValue(instr)
+		}
+	}
+
+	return false
+}
+
+func (a *valueAliases) addValue(v ssa.Value) bool {
+	if a.values[v] {
+		return false
+	}
+	a.values[v] = true
+	return true
+}
+
+func (a *valueAliases) addLoc(l valueLoc) bool {
+	if a.locs[l] {
+		return false
+	}
+	a.locs[l] = true
+	return true
+}
+
+// getLoc returns the location addr points to.
+func (a *valueAliases) getLoc(addr ssa.Value) valueLoc {
+	if fa, ok := addr.(*ssa.FieldAddr); ok {
+		return valueLoc{base: a.canon(fa.X), field: fa.Field}
+	}
+
+	return valueLoc{base: a.canon(addr), field: -1}
+}
+
+// canon returns the value v is known to equal, looking through captured variables,
+// and loads of variables that are only stored to once.
+func (a *valueAliases) canon(v ssa.Value) ssa.Value {
+	for i := 0; i < maxCanonDepth; i++ {
+		switch x := v.(type) {
+		case *ssa.FreeVar:
+			if bound, ok := a.bindings[x]; ok {
+				v = bound
+				continue
+			}
+		case *ssa.UnOp:
+			if x.Op == token.MUL {
+				if stored := getSingleStore(a.canon(x.X)); stored != nil {
+					v = stored
+					continue
+				}
+			}
+		}
+
+		return v
+	}
+
+	return v
+}
+
+// getSingleStore returns the only value stored to the local variable addr, if any.
+func getSingleStore(addr ssa.Value) ssa.Value {
+	alloc, ok := addr.(*ssa.Alloc)
+	if !ok {
+		return nil
+	}
+
+	var stored ssa.Value
+	for _, ref := range *alloc.Referrers() {
+		if store, ok := ref.(*ssa.Store); ok && store.Addr == alloc {
+			if stored != nil {
+				return nil
+			}
+			stored = store.Val
+		}
+	}
+
+	return stored
+}
+
+// callSearch searches SSA functions for paths on which a call is not made on a span.
+type callSearch struct {
+	pass           *analysis.Pass
+	info           *ssaInfo
+	aliases        *valueAliases
+	selName        string
+	ignoreCheckSig *regexp.Regexp
+
+	// checkStatus, if true, makes SetStatus calls only count if they set an error status.
+	checkStatus bool
+
+	// lib, if set, is the span's library, which may count other calls as selName calls.
+	lib *SpanLibrary
+
+	// anyPath, if true, makes calls in deferred functions count if they are made on any
+	// path, like calls that record errors if err != nil. Ends must be made on all paths.
+	anyPath bool
+}
+
+// findMissingCall finds a path through fn, from the instruction after from
+// (or the function's entry if from is nil) to a return, on which the call is
+// never made. Returns are only reported if isMissing is true for them.
+func (s *callSearch) findMissingCall(fn *ssa.Function, from ssa.Instruction, depth int, isMissing func(*ssa.Return) bool) *ssa.Return {
+	if len(fn.Blocks) == 0 {
+		return nil
+	}
+
+	defBlock, rest := fn.Blocks[0], fn.Blocks[0].Instrs
+	if from != nil {
+		defBlock = from.Block()
+		for i, instr := range defBlock.Instrs {
+			if instr == from {
+				rest = defBlock.Instrs[i+1:]
+				break
+			}
+		}
+	}
+
+	// returns reports whether the block, or remainder of a block, returns a missing call.
+	returns := func(instrs []ssa.Instruction) *ssa.Return {
+		if len(instrs) == 0 {
+			return nil
+		}
+
+		if ret, ok := instrs[len(instrs)-1].(*ssa.Return); ok && isMissing(ret) {
+			return ret
+		}
+		return nil
+	}
+
+	// Is the call made in the remainder of its defining block?
+	if s.usesCall(rest, depth) {
+		return nil
+	}
+
+	// Does the defining block return without making the call?
+	if ret := returns(rest); ret != nil || s.neverReturns(rest) {
+		return ret
+	}
+
+	// Search the CFG depth-first for a path, from defBlock to a
+	// return block, in which the call is never made.
+	seen := make(map[*ssa.BasicBlock]bool)
+	var search func(blocks []*ssa.BasicBlock) *ssa.Return
+	search = func(blocks []*ssa.BasicBlock) *ssa.Return {
+		for _, b := range blocks {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+
+			// Prune the search if the block makes the call, or never returns.
+			if s.usesCall(b.Instrs, depth) || s.neverReturns(b.Instrs) {
+				continue
+			}
+
+			// Found path to return statement?
+			if ret := returns(b.Instrs); ret != nil {
+				return ret // found
+			}
+
+			// Recur
+			if ret := search(b.Succs); ret != nil {
+				return ret
+			}
+		}
+		return nil
+	}
+
+	return search(defBlock.Succs)
+}
+
+// neverReturns reports whether instrs contain a call that never returns.
+func (s *callSearch) neverReturns(instrs []ssa.Instruction) bool {
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && s.info.noReturn[call.Pos()] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getCalleeSignature returns the signature of the function called, if it is known statically.
+func getCalleeSignature(call *ssa.CallCommon) string {
+	if call.IsInvoke() {
+		return call.Method.String()
+	}
+
+	if fn := call.StaticCallee(); fn != nil && fn.Object() != nil {
+		return fn.Object().String()
+	}
+
+	return ""
+}
+
+// callsMethod reports whether call calls the selName method on the span.
+func (s *callSearch) callsMethod(call *ssa.CallCommon, selName string) bool {
+	return s.getSpanMethod(call) == selName
+}
+
+// isStartOrReassign returns a function reporting whether an instruction is start, or
+// stores another value to a variable that holds the span, like a span started anew
+// and assigned to the same variable. Loads of the variable after it don't load the span.
+func (s *callSearch) isStartOrReassign(start ssa.Instruction) func(ssa.Instruction) bool {
+	return func(instr ssa.Instruction) bool {
+		if instr == start {
+			return true
+		}
+
+		store, ok := instr.(*ssa.Store)
+		return ok && !s.aliases.has(store.Val) && s.aliases.locs[s.aliases.getLoc(store.Addr)]
+	}
+}
+
+// matchesCall reports whether call is a selName call on the span, or a call the span's
+// library counts as one. known reports whether the library knows call, in which case
+// the facts of the called function don't count.
+func (s *callSearch) matchesCall(call ssa.CallInstruction) (matches, known bool) {
+	common := call.Common()
+	method := s.getSpanMethod(common)
+	if s.checkStatus && s.defersError(call) {
+		return false, true
+	}
+
+	if s.lib == nil || s.lib.callsMethod == nil {
+		return method == s.selName && (!s.checkStatus || s.setsErrorStatus(call)), false
+	}
+
+	if method == "" && !s.passesSpan(common) {
+		return false, false
+	}
+
+	expr, ok := s.info.getCallExprs(call.Parent())[common.Pos()]
+	if !ok {
+		return false, false
+	}
+
+	return s.lib.callsMethod(s.pass.TypesInfo, s.selName, method, expr)
+}
+
+// defersError reports whether call is deferred with an error variable as an argument, like
+// defer span.Finish(tracer.WithError(err)). Arguments of deferred calls are evaluated when the
+// defer statement runs, before the error is set, so they don't set an error status. Calls
+// in deferred function literals see the error that is returned.
+func (s *callSearch) defersError(call ssa.CallInstruction) bool {
+	return s.getDeferredError(call) != nil
+}
+
+// getDeferredError returns the error variable passed as an argument to call, if call is deferred.
+func (s *callSearch) getDeferredError(call ssa.CallInstruction) *ast.Ident {
+	if _, ok := call.(*ssa.Defer); !ok {
+		return nil
+	}
+
+	expr, ok := s.info.getCallExprs(call.Parent())[call.Common().Pos()]
+	if !ok {
+		return nil
+	}
+
+	var errVar *ast.Ident
+	for _, arg := range expr.Args {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && errVar == nil {
+				if v, ok := s.pass.TypesInfo.Uses[id].(*types.Var); ok && isErrorType(v.Type()) {
+					errVar = id
+				}
+			}
+
+			return errVar == nil
+		})
+	}
+
+	return errVar
+}
+
+// setsField reports whether store assigns the selName field of the span, like
+// span.Status = sentry.SpanStatusInternalError. With checkStatus, only error statuses count.
+func (s *callSearch) setsField(store *ssa.Store) bool {
+	if !s.assignsField(store, s.selName) {
+		return false
+	}
+
+	if !s.checkStatus {
+		return true
+	}
+
+	_, isErr := getStoredStatus(store.Val)
+	return isErr
+}
+
+// assignsField reports whether store assigns the field of the span named name.
+func (s *callSearch) assignsField(store *ssa.Store, name string) bool {
+	fa, ok := store.Addr.(*ssa.FieldAddr)
+	return ok && s.aliases.has(fa.X) && getFieldName(fa) == name
+}
+
+// getFieldName returns the name of the field fa points to.
+func getFieldName(fa *ssa.FieldAddr) string {
+	ptr, ok := fa.X.Type().Underlying().(*types.Pointer)
+	if !ok {
+		return ""
+	}
+
+	st, ok := ptr.Elem().Underlying().(*types.Struct)
+	if !ok || fa.Field >= st.NumFields() {
+		return ""
+	}
+
+	return st.Field(fa.Field).Name()
+}
+
+// passesSpan reports whether call passes the span as an argument.
+func (s *callSearch) passesSpan(call *ssa.CallCommon) bool {
+	for _, arg := range call.Args {
+		if s.aliases.has(arg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSpanMethod returns the name of the method call calls on the span, or ""
+// if it does not call a method on the span.
+func (s *callSearch) getSpanMethod(call *ssa.CallCommon) string {
+	if call.IsInvoke() {
+		if s.aliases.has(call.Value) {
+			return call.Method.Name()
+		}
+		return ""
+	}
+
+	fn := call.StaticCallee()
+	if fn == nil || fn.Signature.Recv() == nil || len(call.Args) == 0 || !s.aliases.has(call.Args[0]) {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+// findCall finds a path through fn, from the instruction after from, to a call
+// for which match is true. Paths end at instructions for which stop is true, like
+// the span's start, where the span is started anew.
+func (s *callSearch) findCall(from ssa.Instruction, stop func(ssa.Instruction) bool, match func(ssa.CallInstruction) bool) ssa.CallInstruction {
+	// scan returns the first matching call in instrs, and whether the path continues.
+	scan := func(instrs []ssa.Instruction) (ssa.CallInstruction, bool) {
+		for _, instr := range instrs {
+			if stop(instr) {
+				return nil, false
+			}
+
+			if call, ok := instr.(ssa.CallInstruction); ok && match(call) {
+				return call, false
+			}
+		}
+		return nil, true
+	}
+
+	b := from.Block()
+	for i, instr := range b.Instrs {
+		if instr != from {
+			continue
+		}
+
+		if call, cont := scan(b.Instrs[i+1:]); call != nil || !cont {
+			return call
+		}
+		break
+	}
+
+	// Search the CFG depth-first for a path to a matching call.
+	seen := make(map[*ssa.BasicBlock]bool)
+	var search func(blocks []*ssa.BasicBlock) ssa.CallInstruction
+	search = func(blocks []*ssa.BasicBlock) ssa.CallInstruction {
+		for _, b := range blocks {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+
+			call, cont := scan(b.Instrs)
+			if call != nil {
+				return call // found
+			}
+			if !cont {
+				continue
+			}
+
+			// Recur
+			if call := search(b.Succs); call != nil {
+				return call
+			}
+		}
+		return nil
+	}
+
+	return search(b.Succs)
+}
+
+// isDeferred reports whether the closure mc is only deferred.
+func isDeferred(mc *ssa.MakeClosure) bool {
+	refs := *mc.Referrers()
+	for _, ref := range refs {
+		if d, ok := ref.(*ssa.Defer); !ok || d.Call.Value != mc {
+			return false
+		}
+	}
+
+	return len(refs) > 0
+}
@@ -0,0 +1,139 @@
+package spancheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkStatusConsistency reports returns that don't return an error, but can be
+// reached after an error status was set on the span. Observability platforms then
+// show failures that never happened.
+func checkStatusConsistency(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.setStatusName(),
+		lib:     sv.lib,
+	}
+
+	errIndex := getErrorResultIndex(fn.Signature)
+	returns := getReturnStmts(fn)
+	reported := make(map[*ast.ReturnStmt]bool)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			set := "called with"
+			switch instr := instr.(type) {
+			case *ssa.Call:
+				if !s.setsConstErrorStatus(instr) {
+					continue
+				}
+			case *ssa.Store:
+				// span.Status = sentry.SpanStatusInternalError
+				if _, ok := instr.Val.(*ssa.Const); !ok || !s.assignsField(instr, s.selName) {
+					continue
+				}
+				if _, isErr := getStoredStatus(instr.Val); !isErr {
+					continue
+				}
+				set = "set to"
+			default:
+				continue
+			}
+
+			// Find all returns reached before the status is set again.
+			s.findMissingCall(fn, instr, 0, func(ret *ssa.Return) bool {
+				stmt, ok := returns[ret.Pos()]
+				if !ok {
+					stmt = &ast.ReturnStmt{Return: ret.Pos()}
+				}
+
+				if reported[stmt] || !isSuccessReturn(pass.TypesInfo, stmt, ret, errIndex) {
+					return false
+				}
+				reported[stmt] = true
+
+				pass.ReportRangef(stmt, "return can be reached after %s.%s is %s an error status, but no error is returned", sv.vr.Name(), s.selName, set)
+				return false // find all returns
+			})
+		}
+	}
+}
+
+// setsConstErrorStatus reports whether call sets a constant error status on the span.
+// Libraries with their own status calls, like Datadog's span.SetTag(ext.Error, err), decide
+// which calls set an error status; tags other than the error tag don't.
+func (s *callSearch) setsConstErrorStatus(call *ssa.Call) bool {
+	method := s.getSpanMethod(call.Common())
+	if s.lib.callsMethod != nil {
+		if matches, known := s.matchesCall(call); !matches || !known {
+			return false
+		}
+
+		// ext.Error.Set(span, true) in OpenTracing.
+		if method == "" {
+			return true
+		}
+	} else if method != s.selName {
+		return false
+	}
+
+	expr, ok := s.info.getCallExprs(call.Parent())[call.Common().Pos()]
+	if !ok || !isConstStatus(s.pass.TypesInfo, expr) {
+		return false
+	}
+
+	_, isErr := getStatus(s.pass.TypesInfo, expr)
+	return isErr
+}
+
+// getErrorResultIndex returns the index of the last error result of sig, or -1 if it has none.
+func getErrorResultIndex(sig *types.Signature) int {
+	for i := sig.Results().Len() - 1; i >= 0; i-- {
+		if isErrorType(sig.Results().At(i).Type()) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isSuccessReturn reports whether ret does not return an error: the function has no
+// error result, ret returns the literal nil for it, or ret is a bare return of a named
+// error result that is nil. res is the SSA return of ret.
+func isSuccessReturn(info *types.Info, ret *ast.ReturnStmt, res *ssa.Return, errIndex int) bool {
+	if errIndex < 0 {
+		return true
+	}
+
+	if len(ret.Results) == 0 {
+		return !mayReturnError(res)
+	}
+
+	if errIndex >= len(ret.Results) {
+		return false // a call returning multiple values
+	}
+
+	tv, ok := info.Types[ret.Results[errIndex]]
+	return ok && tv.IsNil()
+}
+
+// isConstStatus reports whether the status code set by call, a call to SetStatus, is a constant.
+func isConstStatus(info *types.Info, call *ast.CallExpr) bool {
+	if len(call.Args) == 0 {
+		return false
+	}
+
+	code := ast.Unparen(call.Args[0])
+	if lit, ok := code.(*ast.CompositeLit); ok {
+		if code = getStatusCode(lit); code == nil {
+			return true // the zero value
+		}
+	}
+
+	tv, ok := info.Types[code]
+	return ok && tv.Value != nil
+}
@@ -0,0 +1,61 @@
+package backgroundcontext
+
+import (
+	"context"
+	"net/http"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span is started with context.Background\\(\\) while ctx is in scope, the span will not be a child of ctx"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := trace.StartSpan(context.TODO(), "bar") // want "span is started with context.TODO\\(\\) while ctx is in scope, the span will not be a child of ctx"
+	defer span.End()
+}
+
+func _(w http.ResponseWriter, r *http.Request) {
+	reqCtx := r.Context()
+
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span is started with context.Background\\(\\) while reqCtx is in scope, the span will not be a child of reqCtx"
+	defer span.End()
+
+	_ = reqCtx
+}
+
+func _(ctx context.Context) {
+	go func() {
+		_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span is started with context.Background\\(\\) while ctx is in scope, the span will not be a child of ctx"
+		defer span.End()
+	}()
+}
+
+// correct
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+}
+
+func _() {
+	ctx, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	_ = ctx
+}
+
+func _(_ context.Context) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+}
@@ -0,0 +1,92 @@
+package contextpropagation
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+func query(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// incorrect
+
+func _(ctx context.Context) error {
+	_, span := otel.Tracer("foo").Start(ctx, "bar") // want "context returned with span is discarded, later calls will not be children of span"
+	defer span.End()
+
+	return query(ctx) // want "ctx is passed while span is in progress, use the context returned with span"
+}
+
+func _(ctx context.Context) error {
+	spanCtx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	if err := query(spanCtx); err != nil {
+		return err
+	}
+
+	return query(ctx) // want "ctx is passed while span is in progress, use the context returned with span"
+}
+
+func _(ctx context.Context) {
+	_, span := trace.StartSpan(ctx, "bar") // want "context returned with span is discarded, later calls will not be children of span"
+	defer span.End()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second) // want "ctx is passed while span is in progress, use the context returned with span"
+	defer cancel()
+
+	_ = query(timeoutCtx)
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "bar") // want "context returned with span is discarded, later calls will not be children of span"
+	defer span.End()
+
+	_ = query(context.Background())
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	return query(ctx)
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	span.AddEvent("foo")
+}
+
+func _(ctx context.Context) error {
+	_, span := otel.Tracer("foo").Start(ctx, "bar")
+	span.End()
+
+	return query(ctx)
+}
+
+func _(ctx context.Context) {
+	for i := 0; i < 3; i++ {
+		childCtx, span := otel.Tracer("foo").Start(ctx, "bar")
+		_ = query(childCtx)
+		span.End()
+	}
+}
+
+func _(ctx context.Context) error {
+	spanCtx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	go func() {
+		_ = query(spanCtx)
+	}()
+
+	return query(spanCtx)
+}
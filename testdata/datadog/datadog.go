@@ -0,0 +1,132 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _() {
+	span := tracer.StartSpan("foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo") // want "span.SetTag is not called on all paths"
+	defer span.Finish()
+
+	if true {
+		err := errors.New("foo")
+		return err // want "return can be reached without calling span.SetTag"
+	}
+
+	return nil
+}
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo") // want "span.SetTag is not called on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.SetTag(ext.ResourceName, "bar")
+	return err // want "return can be reached without calling span.SetTag"
+}
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo") // want "span.SetTag is not called on all paths"
+	defer func() {
+		span.Finish()
+	}()
+
+	return errors.New("foo") // want "return can be reached without calling span.SetTag"
+}
+
+func _(ctx context.Context) (err error) {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+	defer span.Finish(tracer.WithError(err)) // want `span.Finish\(tracer.WithError\(err\)\) is deferred with err evaluated at the defer statement; use defer func\(\) { span.Finish\(tracer.WithError\(err\)\) }\(\)`
+
+	err = errors.New("foo")
+	return err
+}
+
+func _() {
+	span := tracer.StartSpan("foo")
+	span.Finish()
+	span.SetTag("foo", "bar") // want "span.SetTag is called after span.Finish"
+}
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	span.SetTag(ext.Error, true)
+	return nil // want "return can be reached after span.SetTag is called with an error status, but no error is returned"
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	span.SetTag("http.method", "GET")
+	return nil
+}
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.SetTag(ext.Error, err)
+	return err
+}
+
+func _(ctx context.Context) error {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+
+	err := errors.New("foo")
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+func _(ctx context.Context) (err error) {
+	span, _ := tracer.StartSpanFromContext(ctx, "foo")
+	defer func() {
+		span.Finish(tracer.WithError(err))
+	}()
+
+	return errors.New("foo")
+}
+
+func _(ctx context.Context) error {
+	span, ctx := tracer.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	if err := task(ctx); err != nil {
+		span.SetTag("error", err)
+		return err
+	}
+
+	return nil
+}
+
+func task(ctx context.Context) error {
+	return nil
+}
+
+func _() {
+	span := tracer.StartSpan("foo")
+	span.Finish()
+	_ = span.Context()
+	_ = span.BaggageItem("foo")
+}
@@ -0,0 +1,7 @@
+module github.com/jjti/go-spancheck/testdata/datadog
+
+go 1.20
+
+require gopkg.in/DataDog/dd-trace-go.v1 v1.0.0
+
+replace gopkg.in/DataDog/dd-trace-go.v1 => ../stubs/dd-trace-go
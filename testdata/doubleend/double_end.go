@@ -0,0 +1,102 @@
+package doubleend
+
+import (
+	"context"
+	"errors"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// incorrect
+
+func _(fail bool) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if fail {
+		span.End() // want "span.End is called more than once on some paths"
+	}
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+	span.End() // want "span.End is called more than once on some paths"
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "foo")
+	defer span.End()
+
+	defer func() { // want "span.End is called more than once on some paths"
+		span.End()
+	}()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	endSpan(span) // want "span.End is called more than once on some paths"
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	for i := 0; i < 3; i++ {
+		span.End() // want "span.End is called more than once on some paths"
+	}
+}
+
+// correct
+
+func _(fail bool) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	if fail {
+		span.End()
+		return
+	}
+
+	span.End()
+}
+
+func _() {
+	for i := 0; i < 3; i++ {
+		_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+		span.End()
+	}
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+
+	_, span = otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+
+	_, span = otel.Tracer("foo").Start(context.Background(), "bar")
+	defer func() { span.End() }()
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	return errors.New("foo")
+}
+
+func endSpan(span oteltrace.Span) { // want endSpan:"spanParam\\(0: End\\)"
+	span.End()
+}
@@ -18,6 +18,31 @@ func (e *testError) Error() string {
 	return "foo"
 }
 
+type codedError interface {
+	error
+	Code() int
+}
+
+type errorAlias = error
+
+func aliasError() (string, errorAlias) {
+	return "", errors.New("foo")
+}
+
+func interfaceError() (string, interface{ Error() string }) {
+	return "", errors.New("foo")
+}
+
+func genericError[T any, E error](e E) (T, E) {
+	var t T
+	return t, e
+}
+
+func namedError[T any]() (T, codedError) {
+	var t T
+	return t, nil
+}
+
 // incorrect
 
 func _() {
@@ -162,6 +187,111 @@ func _() {
 	fmt.Print(span)
 } // want "return can be reached without calling span.End"
 
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	s := span
+	fmt.Print(s)
+} // want "return can be reached without calling span.End"
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	h := &spanHolder{span: span}
+	defer h.span.End()
+
+	return errors.New("test") // want "return can be reached without calling span.SetStatus"
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	err := errors.New("foo")
+	span.SetStatus(codes.Ok, "") // want "span.SetStatus is called with codes.Ok before returning an error"
+	span.RecordError(err)
+	return err
+}
+
+func _(fail bool) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	err := errors.New("foo")
+	span.RecordError(err)
+	if fail {
+		span.SetStatus(codes.Unset, "") // want "span.SetStatus is called with codes.Unset before returning an error"
+		return err
+	}
+
+	return err // want "return can be reached without calling span.SetStatus"
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(trace.Status{Code: trace.StatusCodeOK}) // want "span.SetStatus is called with trace.StatusCodeOK before returning an error"
+	return errors.New("test")
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(trace.Status{Message: "test"}) // want "span.SetStatus is called with no trace.Status.Code before returning an error"
+	return errors.New("test")
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	if err = errors.New("foo"); err != nil {
+		return // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+	}
+
+	return nil
+}
+
+func _() (s string, err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	defer func() {
+		fmt.Print(s, err)
+	}()
+
+	s, err = "foo", errors.New("foo")
+	return // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
+func _() (string, error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	return aliasError() // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
+func _() (string, interface{ Error() string }) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	return interfaceError() // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
+func _[E error](e E) (string, E) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	return genericError[string](e) // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
+func _[T any]() (T, codedError) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	return namedError[T]() // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
 // correct
 
 func _() error {
@@ -210,7 +340,120 @@ func _() {
 	defer span.End()
 }
 
-func testStartTrace() *trace.Span { // no error expected because this is in extra start types
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	s := span
+	defer s.End()
+}
+
+type spanHolder struct {
+	span *trace.Span
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	h := &spanHolder{span: span}
+	defer h.span.End()
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	h := spanHolder{}
+	h.span = span
+	defer func() {
+		h.span.End()
+	}()
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	s := span
+	if true {
+		s.SetStatus(trace.Status{Code: trace.StatusCodeUnknown})
+		return errors.New("test")
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+func _(code codes.Code) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	err := errors.New("foo")
+	span.SetStatus(code, err.Error())
+	span.RecordError(err)
+	return err
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(trace.Status{trace.StatusCodeInternal, "test"})
+	return errors.New("test")
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	return
+}
+
+func _() (s string, err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if true {
+		s = "foo"
+		return
+	}
+
+	err = nil
+	return
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+	}()
+
+	err = errors.New("foo")
+	return
+}
+
+func _[T any](t T) (T, T) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	return t, t
+}
+
+// no error expected because this is in extra start types, and it returns a span from a span start.
+func testStartTrace() *trace.Span { // want testStartTrace:"spanStart\\(opencensus\\)"
 	_, span := trace.StartSpan(context.Background(), "bar")
 	return span
 }
@@ -239,17 +482,17 @@ func _() error {
 	return errors.New("test")
 }
 
-// TODO: the check below now fails after the change to fix issue 25 above.
-// func _() error {
-// 	var span *trace.Span
+// The span variable is in scope of the function's return.
+func _() error {
+	var span *trace.Span
 
-// 	if true {
-// 		_, span = trace.StartSpan(context.Background(), "foo")
-// 		defer span.End()
-// 	}
+	if true {
+		_, span = trace.StartSpan(context.Background(), "foo") // want "span.SetStatus is not called on all paths"
+		defer span.End()
+	}
 
-// 	return errors.New("test")
-// }
+	return errors.New("test") // want "return can be reached without calling span.SetStatus"
+}
 
 // https://github.com/jjti/go-spancheck/issues/24
 func _() (err error) {
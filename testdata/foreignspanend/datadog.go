@@ -0,0 +1,23 @@
+package foreignspanend
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if ok {
+		span.Finish() // want "span from tracer.SpanFromContext is ended, it is owned by the code that started it"
+	}
+}
+
+// correct
+
+func _(ctx context.Context) {
+	span, _ := tracer.SpanFromContext(ctx)
+	span.SetTag("foo", "bar")
+}
@@ -0,0 +1,49 @@
+package foreignspanend
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func endSpan(span oteltrace.Span) { // want endSpan:"spanParam\\(0: End\\)"
+	span.End()
+}
+
+// incorrect
+
+func _(ctx context.Context) {
+	oteltrace.SpanFromContext(ctx).End() // want "span from trace.SpanFromContext is ended, it is owned by the code that started it"
+}
+
+func _(ctx context.Context) {
+	span := oteltrace.SpanFromContext(ctx)
+	defer span.End() // want "span from trace.SpanFromContext is ended, it is owned by the code that started it"
+
+	span.AddEvent("foo")
+}
+
+func _(ctx context.Context) {
+	span := trace.FromContext(ctx)
+	span.End() // want "span from trace.FromContext is ended, it is owned by the code that started it"
+}
+
+func _(ctx context.Context) {
+	endSpan(oteltrace.SpanFromContext(ctx)) // want "span from trace.SpanFromContext is ended, it is owned by the code that started it"
+}
+
+// correct
+
+func _(ctx context.Context) {
+	span := oteltrace.SpanFromContext(ctx)
+	span.AddEvent("foo")
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	oteltrace.SpanFromContext(ctx).AddEvent("foo")
+}
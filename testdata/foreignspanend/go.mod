@@ -0,0 +1,23 @@
+module github.com/jjti/go-spancheck/testdata/foreignspanend
+
+go 1.20
+
+require (
+	github.com/opentracing/opentracing-go v1.2.0
+	go.opentelemetry.io/otel v1.21.0
+	gopkg.in/DataDog/dd-trace-go.v1 v1.0.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
+	go.opencensus.io v0.24.0 // indirect
+	go.opentelemetry.io/otel/metric v1.21.0 // indirect
+	go.opentelemetry.io/otel/trace v1.21.0 // indirect
+)
+
+replace (
+	github.com/opentracing/opentracing-go => ../stubs/opentracing-go
+	gopkg.in/DataDog/dd-trace-go.v1 => ../stubs/dd-trace-go
+)
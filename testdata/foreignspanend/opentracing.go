@@ -0,0 +1,22 @@
+package foreignspanend
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	span := opentracing.SpanFromContext(ctx)
+	defer span.Finish() // want "span from opentracing.SpanFromContext is ended, it is owned by the code that started it"
+
+	span.SetTag("foo", "bar")
+}
+
+// correct
+
+func _(ctx context.Context) {
+	opentracing.SpanFromContext(ctx).SetTag("foo", "bar")
+}
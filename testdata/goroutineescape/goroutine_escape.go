@@ -0,0 +1,200 @@
+package goroutineescape
+
+import (
+	"context"
+	"sync"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+func annotate(span oteltrace.Span) {
+	span.AddEvent("foo")
+}
+
+// incorrect
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	go func() { // want "span is used by a goroutine, which may run after the deferred span.End"
+		span.AddEvent("foo")
+	}()
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	go span.AddAttributes(trace.StringAttribute("foo", "bar")) // want "span is used by a goroutine, which may run after the deferred span.End"
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	go annotate(span) // want "span is used by a goroutine, which may run after the deferred span.End"
+}
+
+func _(ctx context.Context) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error { // want "span is used by a goroutine, which may run after the deferred span.End"
+		annotate(span)
+		return ctx.Err()
+	})
+
+	return nil
+}
+
+func _(ctx context.Context, wait bool) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error { // want "span is used by a goroutine, which may run after the deferred span.End"
+		span.AddEvent("foo")
+		return ctx.Err()
+	})
+
+	if wait {
+		return g.Wait()
+	}
+	return nil
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { // want "span is used by a goroutine, which may run after the deferred span.End"
+		defer wg.Done()
+		span.AddEvent("foo")
+	}()
+}
+
+func _(wait bool) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	wg.Go(func() { // want "span is used by a goroutine, which may run after the deferred span.End"
+		span.AddEvent("foo")
+	})
+
+	if wait {
+		wg.Wait()
+	}
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return ctx.Err()
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	span.AddEvent("foo")
+	return nil
+}
+
+func _(ctx context.Context) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		span.AddEvent("foo")
+		return ctx.Err()
+	})
+
+	return g.Wait()
+}
+
+func _(ctx context.Context) error {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	var g errgroup.Group
+	g.Go(func() error {
+		annotate(span)
+		return ctx.Err()
+	})
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+
+	go func() {
+		defer span.End()
+		span.AddEvent("foo")
+	}()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	go func() {
+		_ = span.SpanContext()
+	}()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		span.AddEvent("foo")
+	}()
+	wg.Wait()
+}
+
+func _(n int) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			annotate(span)
+		}()
+	}
+	wg.Wait()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		span.AddEvent("foo")
+	})
+	wg.Wait()
+}
@@ -0,0 +1,148 @@
+package interprocedural
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/jjti/go-spancheck/testdata/interprocedural/telemetry"
+)
+
+// incorrect
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	telemetry.EndIfRecording(span)
+} // want "return can be reached without calling span.End"
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer telemetry.End(span)
+
+	err := errors.New("foo")
+	setStatusSometimes(span, err)
+	return err // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	err := errors.New("foo")
+	span.RecordError(err)
+	markOK(span)
+	return err // want "return can be reached without calling span.SetStatus"
+}
+
+func markOK(span oteltrace.Span) {
+	span.SetStatus(codes.Ok, "")
+}
+
+func setStatusSometimes(span oteltrace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func _() {
+	_, span := telemetry.StartSpan(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	fmt.Print(span)
+} // want "return can be reached without calling span.End"
+
+func _() {
+	span, _ := telemetry.StartSpanOrErr(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	fmt.Print(span)
+} // want "return can be reached without calling span.End"
+
+func _() {
+	span := telemetry.StartCensusSpan(context.Background()) // want "span.End is not called on all paths, possible memory leak"
+	fmt.Print(span)
+} // want "return can be reached without calling span.End"
+
+func _() {
+	_, span := startSpan(context.Background()) // want "span.End is not called on all paths, possible memory leak"
+	fmt.Print(span)
+} // want "return can be reached without calling span.End"
+
+// correct
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer telemetry.End(span)
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer telemetry.End(span)
+
+	if err := errors.New("foo"); err != nil {
+		return telemetry.Record(span, err)
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+
+	if err := errors.New("foo"); err != nil {
+		telemetry.RecordAndEnd(span, err)
+		return err
+	}
+
+	span.End()
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer endSpan(span)
+
+	if err := errors.New("foo"); err != nil {
+		recordErr(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer telemetry.EndCensus(span)
+}
+
+func _() {
+	_, span := telemetry.StartSpan(context.Background(), "bar")
+	defer span.End()
+}
+
+func _() {
+	span := telemetry.StartCensusSpan(context.Background())
+	defer span.End()
+}
+
+// helpers declared after their callers in the same package
+
+func startSpan(ctx context.Context) (context.Context, oteltrace.Span) { // want startSpan:"spanStart\\(opentelemetry\\)"
+	ctx, span := telemetry.StartSpan(ctx, "bar")
+	return ctx, span
+}
+
+func recordErr(span oteltrace.Span, err error) { // want recordErr:"spanParam\\(0: SetStatus, RecordError\\)"
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.SetStatus(codes.Error, "failed")
+	span.RecordError(err)
+}
+
+func endSpan(span oteltrace.Span) { // want endSpan:"spanParam\\(0: End\\)"
+	defer span.End()
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// StartSpan starts a span with the package's tracer.
+func StartSpan(ctx context.Context, name string) (context.Context, oteltrace.Span) {
+	return otel.Tracer("telemetry").Start(ctx, name)
+}
+
+// StartSpanOrErr wraps StartSpan, returning the span first.
+func StartSpanOrErr(ctx context.Context, name string) (oteltrace.Span, error) {
+	_, span := StartSpan(ctx, name)
+	return span, nil
+}
+
+// StartCensusSpan starts an OpenCensus span.
+func StartCensusSpan(ctx context.Context) *trace.Span {
+	_, span := trace.StartSpan(ctx, "telemetry")
+	return span
+}
+
+// Record sets an error status and records err on the span.
+func Record(span oteltrace.Span, err error) error {
+	span.SetStatus(codes.Error, err.Error())
+	span.RecordError(err)
+	return err
+}
+
+// End ends the span.
+func End(span oteltrace.Span) {
+	span.End()
+}
+
+// RecordAndEnd records err on the span before ending it.
+func RecordAndEnd(span oteltrace.Span, err error) {
+	_ = Record(span, err)
+	End(span)
+}
+
+// EndIfRecording only ends the span on some paths.
+func EndIfRecording(span oteltrace.Span) {
+	if span.IsRecording() {
+		span.End()
+	}
+}
+
+// EndCensus ends an OpenCensus span.
+func EndCensus(span *trace.Span) {
+	span.End()
+}
@@ -0,0 +1,108 @@
+package loopdeferend
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+func process(ctx context.Context, item string) bool {
+	return item != ""
+}
+
+// incorrect
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		ctx, span := otel.Tracer("foo").Start(ctx, "bar") // want "span.End is not called before the next loop iteration"
+		defer span.End()                                  // want "span.End is deferred in a loop, spans are not ended until the function returns"
+
+		process(ctx, item)
+	}
+}
+
+func _(ctx context.Context) {
+	for i := 0; i < 10; i++ {
+		_, span := trace.StartSpan(ctx, "bar") // want "span.End is not called before the next loop iteration"
+		defer func() {                         // want "span.End is deferred in a loop, spans are not ended until the function returns"
+			span.End()
+		}()
+	}
+}
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		ctx, span := otel.Tracer("foo").Start(ctx, "bar") // want "span.End is not called before the next loop iteration"
+		if !process(ctx, item) {
+			continue
+		}
+
+		span.End()
+	}
+}
+
+func _(ctx context.Context, items []string) {
+	for range items {
+		for _, item := range items {
+			ctx, span := otel.Tracer("foo").Start(ctx, "bar") // want "span.End is not called before the next loop iteration"
+			if process(ctx, item) {
+				break
+			}
+			span.End()
+		}
+	}
+}
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		ctx, span := otel.Tracer("foo").Start(ctx, "bar") // want "span.End is not called before the next loop iteration"
+		defer span.End()                                  // want "span.End is deferred in a loop, spans are not ended until the function returns"
+
+		if process(ctx, item) {
+			return
+		}
+	}
+}
+
+// correct
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+		process(ctx, item)
+		span.End()
+	}
+}
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		func() {
+			ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+			defer span.End()
+
+			process(ctx, item)
+		}()
+	}
+}
+
+func _(ctx context.Context, items []string) {
+	for _, item := range items {
+		ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+		if !process(ctx, item) {
+			span.End()
+			continue
+		}
+
+		span.End()
+	}
+}
+
+func _(ctx context.Context) {
+	ctx, span := otel.Tracer("foo").Start(ctx, "bar")
+	defer span.End()
+
+	for i := 0; i < 10; i++ {
+		process(ctx, "")
+	}
+}
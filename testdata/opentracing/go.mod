@@ -0,0 +1,7 @@
+module github.com/jjti/go-spancheck/testdata/opentracing
+
+go 1.20
+
+require github.com/opentracing/opentracing-go v1.2.0
+
+replace github.com/opentracing/opentracing-go => ../stubs/opentracing-go
@@ -0,0 +1,145 @@
+package opentracing
+
+import (
+	"context"
+	"errors"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(tracer opentracing.Tracer) {
+	span := tracer.StartSpan("foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo") // want "span.SetTag is not called on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.LogFields(log.Error(err))
+	return err // want "return can be reached without calling span.SetTag"
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo") // want "span.SetTag is not called on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	ext.Error.Set(span, false)
+	span.LogFields(log.Error(err))
+	return err // want "return can be reached without calling span.SetTag"
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo") // want "span.LogFields is not called on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	ext.Error.Set(span, true)
+	span.LogFields(log.String("foo", "bar"))
+	return err // want "return can be reached without calling span.LogFields"
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo") // want "span.LogFields is not called on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.SetTag(string(ext.Error), true)
+	return err // want "return can be reached without calling span.LogFields"
+}
+
+func _() {
+	span := opentracing.StartSpan("foo")
+	span.Finish()
+	span.SetTag("foo", "bar") // want "span.SetTag is called after span.Finish"
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	span.SetTag("error", true)
+	return nil // want "return can be reached after span.SetTag is called with an error status, but no error is returned"
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	ext.Error.Set(span, true)
+	return nil // want "return can be reached after span.SetTag is called with an error status, but no error is returned"
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	span.SetTag("component", "db")
+	return nil
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	ext.LogError(span, err)
+	return err
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	ext.Error.Set(span, true)
+	span.LogFields(log.Event("error"), log.Error(err))
+	return err
+}
+
+func _(ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.SetTag("error", true)
+	span.LogFields(log.Error(err))
+	return err
+}
+
+func _(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "foo")
+	defer func() {
+		if err != nil {
+			ext.LogError(span, err)
+		}
+		span.Finish()
+	}()
+
+	return task(ctx)
+}
+
+func task(ctx context.Context) error {
+	return nil
+}
+
+func _() {
+	span := opentracing.StartSpan("foo")
+	span.Finish()
+	_ = span.Context()
+	_ = span.BaggageItem("foo")
+	_ = span.Tracer()
+}
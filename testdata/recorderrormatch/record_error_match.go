@@ -0,0 +1,162 @@
+package recorderrormatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+)
+
+func task() error {
+	return errors.New("foo")
+}
+
+func values() (string, error) {
+	return "", errors.New("foo")
+}
+
+// incorrect
+
+func _(otherErr error) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("wrap: %w", otherErr) // want "returned error does not wrap err, recorded with span.RecordError"
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	err := task()
+	_, otherErr := values()
+	if err != nil {
+		span.RecordError(err)
+		return otherErr // want "returned error does not wrap err, recorded with span.RecordError"
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("wrap: %v", err) // want "returned error does not wrap err, recorded with span.RecordError"
+	}
+
+	return nil
+}
+
+func _(otherErr error) (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if taskErr := task(); taskErr != nil {
+		span.RecordError(taskErr)
+		err = otherErr
+		return // want "returned error does not wrap taskErr, recorded with span.RecordError"
+	}
+
+	return nil
+}
+
+// correct
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func _() (string, error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("wrap: %w", err)
+	}
+
+	return "", nil
+}
+
+func _(otherErr error) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return errors.Join(otherErr, err)
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		wrapped := fmt.Errorf("wrap: %w", err)
+		return wrapped
+	}
+
+	return nil
+}
+
+func _(otherErr error) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		span.RecordError(otherErr)
+		return otherErr
+	}
+
+	return nil
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err = task(); err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := task(); err != nil {
+		span.RecordError(err)
+		return wrap(err)
+	}
+
+	return nil
+}
+
+func wrap(err error) error {
+	return fmt.Errorf("wrap: %w", err)
+}
@@ -0,0 +1,7 @@
+module github.com/jjti/go-spancheck/testdata/sentry
+
+go 1.20
+
+require github.com/getsentry/sentry-go v0.27.0
+
+replace github.com/getsentry/sentry-go => ../stubs/sentry-go
@@ -0,0 +1,102 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	span := sentry.StartSpan(ctx, "foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(ctx context.Context) {
+	tx := sentry.StartTransaction(ctx, "foo")
+	defer tx.Finish()
+
+	span := tx.StartChild("bar") // want "span.Finish is not called on all paths, possible memory leak"
+	span.SetTag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(ctx context.Context) error {
+	span := sentry.StartSpan(ctx, "foo") // want "span.Status is not set on all paths"
+	defer span.Finish()
+
+	err := errors.New("foo")
+	return err // want "return can be reached without setting span.Status"
+}
+
+func _(ctx context.Context) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.Status = sentry.SpanStatusOK // want "span.Status is set to sentry.SpanStatusOK before returning an error"
+	return err
+}
+
+func _(ctx context.Context) {
+	span := sentry.StartSpan(ctx, "foo")
+	span.Finish()
+	span.SetTag("foo", "bar") // want "span.SetTag is called after span.Finish"
+}
+
+func _(ctx context.Context) {
+	span := sentry.SpanFromContext(ctx)
+	span.Finish() // want "span from sentry.SpanFromContext is ended, it is owned by the code that started it"
+}
+
+func _(ctx context.Context) {
+	sentry.TransactionFromContext(ctx).Finish() // want "span from sentry.TransactionFromContext is ended, it is owned by the code that started it"
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("foo")
+	span.Status = sentry.SpanStatusInternalError
+	return err
+}
+
+func _(ctx context.Context) (err error) {
+	span := sentry.StartSpan(ctx, "foo")
+	defer func() {
+		if err != nil {
+			span.Status = sentry.SpanStatusUnknown
+		}
+		span.Finish()
+	}()
+
+	return task(span.Context())
+}
+
+func _(ctx context.Context) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	if err := task(span.Context()); err != nil {
+		span.Status = sentry.SpanStatusInternalError
+		return err
+	}
+
+	span.Status = sentry.SpanStatusOK
+	return nil
+}
+
+func task(ctx context.Context) error {
+	return nil
+}
+
+func _(ctx context.Context) {
+	span := sentry.StartSpan(ctx, "foo")
+	span.Finish()
+	_ = span.ToSentryTrace()
+	_ = span.Context()
+}
@@ -0,0 +1,3 @@
+module github.com/jjti/go-spancheck/testdata/spanlibrary
+
+go 1.20
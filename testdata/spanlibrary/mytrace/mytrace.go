@@ -0,0 +1,31 @@
+// Package mytrace is a tracing library with its own span methods.
+package mytrace
+
+import "context"
+
+// Span is a span of mytrace.
+type Span struct{}
+
+// Start starts a span.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return ctx, &Span{}
+}
+
+// FromContext returns the span in ctx, if any.
+func FromContext(ctx context.Context) *Span {
+	return &Span{}
+}
+
+// ID returns the span's ID.
+func (s *Span) ID() string {
+	return ""
+}
+
+// Finish ends the span.
+func (s *Span) Finish() {}
+
+// Fail records err on the span.
+func (s *Span) Fail(err error) {}
+
+// Tag sets a tag on the span.
+func (s *Span) Tag(key, value string) {}
@@ -0,0 +1,86 @@
+package spanlibrary
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jjti/go-spancheck/testdata/spanlibrary/mytrace"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	_, span := mytrace.Start(ctx, "foo") // want "span.Finish is not called on all paths, possible memory leak"
+	span.Tag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+func _(ctx context.Context) error {
+	_, span := mytrace.Start(ctx, "foo") // want "span.Fail is not called on all paths"
+	defer span.Finish()
+
+	return errors.New("err") // want "return can be reached without calling span.Fail"
+}
+
+func _(ctx context.Context) {
+	_, span := mytrace.Start(ctx, "foo")
+	span.Finish()
+	span.Tag("foo", "bar") // want "span.Tag is called after span.Finish"
+}
+
+func _(ctx context.Context) {
+	span := mytrace.FromContext(ctx)
+	span.Finish() // want "span from mytrace.FromContext is ended, it is owned by the code that started it"
+}
+
+func _(ctx context.Context) {
+	_, span := startTrace(ctx) // want "span.Finish is not called on all paths, possible memory leak"
+	span.Tag("foo", "bar")
+} // want "return can be reached without calling span.Finish"
+
+// correct
+
+func _(ctx context.Context) error {
+	_, span := mytrace.Start(ctx, "foo")
+	defer span.Finish()
+
+	err := errors.New("err")
+	span.Fail(err)
+	return err
+}
+
+func _(ctx context.Context) error {
+	_, span := mytrace.Start(ctx, "foo")
+	defer span.Finish()
+
+	return fail(span, errors.New("err"))
+}
+
+func _(ctx context.Context) error {
+	_, span := mytrace.Start(ctx, "foo")
+	defer func() {
+		span.Finish()
+	}()
+
+	return nil
+}
+
+func _(ctx context.Context) string {
+	_, span := mytrace.Start(ctx, "foo")
+	span.Finish()
+	return span.ID()
+}
+
+// background-context and span-name don't apply to mytrace.
+func _(ctx context.Context, name string) {
+	_, span := mytrace.Start(context.Background(), name)
+	defer span.Finish()
+}
+
+func startTrace(ctx context.Context) (context.Context, *mytrace.Span) {
+	return ctx, &mytrace.Span{}
+}
+
+func fail(span *mytrace.Span, err error) error { // want fail:"spanParam\\(0: Fail\\)"
+	span.Fail(err)
+	return err
+}
@@ -0,0 +1,45 @@
+package spanname
+
+import (
+	"context"
+	"fmt"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+const getUserSpan = "users.get_user"
+
+// incorrect
+
+func _(ctx context.Context, id int) {
+	_, span := otel.Tracer("foo").Start(ctx, fmt.Sprintf("get user %d", id)) // want "span name is not a constant, span names should have a low cardinality"
+	defer span.End()
+}
+
+func _(ctx context.Context, method string) {
+	_, span := trace.StartSpan(ctx, "users."+method) // want "span name is not a constant, span names should have a low cardinality"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "GetUser") // want "span name \"GetUser\" does not match \\^\\[a-z\\]\\+\\(\\\\.\\[a-z_\\]\\+\\)\\*\\$"
+	defer span.End()
+}
+
+// correct
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "users.get_user")
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := trace.StartSpan(ctx, getUserSpan)
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("foo").Start(ctx, "users"+".get_user")
+	defer span.End()
+}
@@ -0,0 +1,99 @@
+package spannesting
+
+import (
+	"context"
+	"errors"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+
+	parent.End() // want "parent.End is called before child.End, child spans should end before their parent"
+	child.End()
+}
+
+func _(ctx context.Context) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+	defer child.End()
+
+	parent.End() // want "parent.End is called before child.End, child spans should end before their parent"
+}
+
+func _(ctx context.Context) {
+	ctx, parent := trace.StartSpan(ctx, "parent")
+	_, child := trace.StartSpan(ctx, "child")
+	defer child.End()
+	defer parent.End() // want "parent.End is called before child.End, child spans should end before their parent"
+}
+
+func _(ctx context.Context, fail bool) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+
+	if fail {
+		parent.End() // want "parent.End is called before child.End, child spans should end before their parent"
+		child.End()
+		return
+	}
+
+	child.End()
+	parent.End()
+}
+
+// correct
+
+func _(ctx context.Context, fail bool) error {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	if fail {
+		parent.End()
+		return errors.New("fail")
+	}
+
+	defer parent.End()
+
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+	defer child.End()
+
+	return nil
+}
+
+func _(ctx context.Context) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	defer parent.End()
+
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+	defer child.End()
+}
+
+func _(ctx context.Context) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	_, child := otel.Tracer("foo").Start(ctx, "child")
+
+	child.End()
+	parent.End()
+}
+
+func _(ctx context.Context) {
+	_, first := otel.Tracer("foo").Start(ctx, "first")
+	_, second := otel.Tracer("foo").Start(ctx, "second")
+
+	first.End()
+	second.End()
+}
+
+func _(ctx context.Context) {
+	ctx, parent := otel.Tracer("foo").Start(ctx, "parent")
+	defer parent.End()
+
+	for i := 0; i < 3; i++ {
+		_, child := otel.Tracer("foo").Start(ctx, "child")
+		child.End()
+	}
+}
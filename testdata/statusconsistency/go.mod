@@ -0,0 +1,19 @@
+module github.com/jjti/go-spancheck/testdata/statusconsistency
+
+go 1.20
+
+require (
+	github.com/getsentry/sentry-go v0.27.0
+	go.opentelemetry.io/otel v1.21.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
+	go.opencensus.io v0.24.0 // indirect
+	go.opentelemetry.io/otel/metric v1.21.0 // indirect
+	go.opentelemetry.io/otel/trace v1.21.0 // indirect
+)
+
+replace github.com/getsentry/sentry-go => ../stubs/sentry-go
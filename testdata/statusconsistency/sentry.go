@@ -0,0 +1,45 @@
+package statusconsistency
+
+import (
+	"context"
+	"errors"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// incorrect
+
+func _(ctx context.Context, fail bool) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	if fail {
+		span.Status = sentry.SpanStatusInternalError
+	}
+
+	return nil // want "return can be reached after span.Status is set to an error status, but no error is returned"
+}
+
+// correct
+
+func _(ctx context.Context, fail bool) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	if fail {
+		span.Status = sentry.SpanStatusInternalError
+		return errors.New("foo")
+	}
+
+	span.Status = sentry.SpanStatusOK
+	return nil
+}
+
+func _(ctx context.Context) error {
+	span := sentry.StartSpan(ctx, "foo")
+	defer span.Finish()
+
+	span.Status = sentry.SpanStatusInternalError
+	span.Status = sentry.SpanStatusOK
+	return nil
+}
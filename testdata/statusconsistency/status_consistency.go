@@ -0,0 +1,113 @@
+package statusconsistency
+
+import (
+	"context"
+	"errors"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// incorrect
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return nil // want "return can be reached after span.SetStatus is called with an error status, but no error is returned"
+}
+
+func _() (string, error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(codes.Error, "foo")
+	return "", nil // want "return can be reached after span.SetStatus is called with an error status, but no error is returned"
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return // want "return can be reached after span.SetStatus is called with an error status, but no error is returned"
+	}
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(trace.Status{Code: trace.StatusCodeInternal})
+	return nil // want "return can be reached after span.SetStatus is called with an error status, but no error is returned"
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(codes.Error, "foo")
+	return // want "return can be reached after span.SetStatus is called with an error status, but no error is returned"
+}
+
+// correct
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+func _(err error) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(codes.Error, "foo")
+	return err
+}
+
+func _(code codes.Code) error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(code, "foo")
+	return nil
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.SetStatus(codes.Error, "foo")
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+func _() (err error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	err = errors.New("foo")
+	span.SetStatus(codes.Error, err.Error())
+	return
+}
@@ -0,0 +1,56 @@
+// Package xray is a stub of github.com/aws/aws-xray-sdk-go/xray.
+package xray
+
+import "context"
+
+type contextKey struct{}
+
+// Segment is a segment, or subsegment, of a trace.
+type Segment struct {
+	Name  string
+	Fault bool
+}
+
+// BeginSegment starts a segment.
+func BeginSegment(ctx context.Context, name string) (context.Context, *Segment) {
+	seg := &Segment{Name: name}
+	return context.WithValue(ctx, contextKey{}, seg), seg
+}
+
+// BeginSubsegment starts a subsegment of the segment in ctx.
+func BeginSubsegment(ctx context.Context, name string) (context.Context, *Segment) {
+	seg := &Segment{Name: name}
+	return context.WithValue(ctx, contextKey{}, seg), seg
+}
+
+// GetSegment returns the segment in ctx.
+func GetSegment(ctx context.Context) *Segment {
+	seg, _ := ctx.Value(contextKey{}).(*Segment)
+	return seg
+}
+
+// Capture runs fn in a subsegment, which is closed with the error fn returns.
+func Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, seg := BeginSubsegment(ctx, name)
+	err := fn(ctx)
+	seg.Close(err)
+	return err
+}
+
+// Close ends the segment, marking it as failed if err is not nil.
+func (seg *Segment) Close(err error) {
+	if err != nil {
+		seg.Fault = true
+	}
+}
+
+// AddAnnotation adds an indexed annotation to the segment.
+func (seg *Segment) AddAnnotation(key string, value interface{}) error {
+	return nil
+}
+
+// AddError marks the segment as failed.
+func (seg *Segment) AddError(err error) error {
+	seg.Fault = true
+	return nil
+}
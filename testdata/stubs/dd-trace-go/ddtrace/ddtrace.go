@@ -0,0 +1,33 @@
+// Package ddtrace is a stub of gopkg.in/DataDog/dd-trace-go.v1/ddtrace.
+package ddtrace
+
+// Span is a span.
+type Span interface {
+	SetTag(key string, value interface{})
+	SetOperationName(operationName string)
+	BaggageItem(key string) string
+	Finish(opts ...FinishOption)
+	Context() SpanContext
+}
+
+// SpanContext identifies a span.
+type SpanContext interface {
+	SpanID() uint64
+	TraceID() uint64
+}
+
+// FinishConfig holds the configuration of a span's finish.
+type FinishConfig struct {
+	Error error
+}
+
+// FinishOption configures a span's finish.
+type FinishOption func(cfg *FinishConfig)
+
+// StartSpanConfig holds the configuration of a span's start.
+type StartSpanConfig struct {
+	Tags map[string]interface{}
+}
+
+// StartSpanOption configures a span's start.
+type StartSpanOption func(cfg *StartSpanConfig)
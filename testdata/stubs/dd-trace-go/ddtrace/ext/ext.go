@@ -0,0 +1,10 @@
+// Package ext is a stub of gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext.
+package ext
+
+const (
+	// Error is the tag of a span's error.
+	Error = "error"
+
+	// ResourceName is the tag of a span's resource.
+	ResourceName = "resource.name"
+)
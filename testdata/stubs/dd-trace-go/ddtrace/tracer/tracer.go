@@ -0,0 +1,43 @@
+// Package tracer is a stub of gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer.
+package tracer
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+)
+
+type span struct{}
+
+func (*span) SetTag(key string, value interface{})  {}
+func (*span) SetOperationName(operationName string) {}
+func (*span) BaggageItem(key string) string         { return "" }
+func (*span) Finish(opts ...ddtrace.FinishOption)   {}
+func (*span) Context() ddtrace.SpanContext          { return nil }
+
+// StartSpan starts a span.
+func StartSpan(operationName string, opts ...ddtrace.StartSpanOption) ddtrace.Span {
+	return &span{}
+}
+
+// StartSpanFromContext starts a span that is a child of the span in ctx, if any.
+func StartSpanFromContext(ctx context.Context, operationName string, opts ...ddtrace.StartSpanOption) (ddtrace.Span, context.Context) {
+	return &span{}, ctx
+}
+
+// SpanFromContext returns the span in ctx, if any.
+func SpanFromContext(ctx context.Context) (ddtrace.Span, bool) {
+	return &span{}, true
+}
+
+// WithError marks the span as failed with err.
+func WithError(err error) ddtrace.FinishOption {
+	return func(cfg *ddtrace.FinishConfig) {
+		cfg.Error = err
+	}
+}
+
+// ResourceName sets the span's resource name.
+func ResourceName(name string) ddtrace.StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {}
+}
@@ -0,0 +1,3 @@
+module gopkg.in/DataDog/dd-trace-go.v1
+
+go 1.20
@@ -0,0 +1,38 @@
+// Package ext is a stub of github.com/opentracing/opentracing-go/ext.
+package ext
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var (
+	// Error marks a span as failed.
+	Error = boolTagName("error")
+
+	// Component is the tag of the component that created a span.
+	Component = stringTagName("component")
+)
+
+type boolTagName string
+
+// Set sets the tag on span.
+func (tag boolTagName) Set(span opentracing.Span, value bool) {
+	span.SetTag(string(tag), value)
+}
+
+type stringTagName string
+
+// Set sets the tag on span.
+func (tag stringTagName) Set(span opentracing.Span, value string) {
+	span.SetTag(string(tag), value)
+}
+
+// LogError marks span as failed and logs err.
+func LogError(span opentracing.Span, err error, fields ...log.Field) {
+	if err == nil {
+		return
+	}
+	Error.Set(span, true)
+	span.LogFields(append([]log.Field{log.Event("error"), log.Error(err)}, fields...)...)
+}
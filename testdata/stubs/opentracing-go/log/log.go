@@ -0,0 +1,23 @@
+// Package log is a stub of github.com/opentracing/opentracing-go/log.
+package log
+
+// Field is a key-value pair logged on a span.
+type Field struct {
+	key   string
+	value interface{}
+}
+
+// String returns a string field.
+func String(key, val string) Field {
+	return Field{key, val}
+}
+
+// Error returns an error field.
+func Error(err error) Field {
+	return Field{"error.object", err}
+}
+
+// Event returns an event field.
+func Event(val string) Field {
+	return Field{"event", val}
+}
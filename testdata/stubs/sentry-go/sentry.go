@@ -0,0 +1,87 @@
+// Package sentry is a stub of github.com/getsentry/sentry-go.
+package sentry
+
+import "context"
+
+// SpanStatus is the status of a span.
+type SpanStatus uint8
+
+// Span statuses.
+const (
+	SpanStatusUndefined SpanStatus = iota
+	SpanStatusOK
+	SpanStatusCanceled
+	SpanStatusUnknown
+	SpanStatusInvalidArgument
+	SpanStatusDeadlineExceeded
+	SpanStatusNotFound
+	SpanStatusAlreadyExists
+	SpanStatusPermissionDenied
+	SpanStatusResourceExhausted
+	SpanStatusFailedPrecondition
+	SpanStatusAborted
+	SpanStatusOutOfRange
+	SpanStatusUnimplemented
+	SpanStatusInternalError
+	SpanStatusUnavailable
+	SpanStatusDataLoss
+	SpanStatusUnauthenticated
+)
+
+// SpanOption configures a span.
+type SpanOption func(*Span)
+
+// Span is a span, or a transaction, of a trace.
+type Span struct {
+	Op          string
+	Description string
+	Status      SpanStatus
+
+	ctx context.Context
+}
+
+// StartSpan starts a span.
+func StartSpan(ctx context.Context, operation string, options ...SpanOption) *Span {
+	span := &Span{Op: operation}
+	span.ctx = context.WithValue(ctx, spanContextKey{}, span)
+	return span
+}
+
+// StartTransaction starts a transaction.
+func StartTransaction(ctx context.Context, name string, options ...SpanOption) *Span {
+	return StartSpan(ctx, name, options...)
+}
+
+// StartChild starts a child of the span.
+func (s *Span) StartChild(operation string, options ...SpanOption) *Span {
+	return StartSpan(s.ctx, operation, options...)
+}
+
+// Context returns the context of the span.
+func (s *Span) Context() context.Context {
+	return s.ctx
+}
+
+// SetTag sets a tag on the span.
+func (s *Span) SetTag(name, value string) {}
+
+// ToSentryTrace returns the sentry-trace header of the span.
+func (s *Span) ToSentryTrace() string {
+	return ""
+}
+
+// SpanFromContext returns the span in ctx, if any.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// TransactionFromContext returns the transaction in ctx, if any.
+func TransactionFromContext(ctx context.Context) *Span {
+	return SpanFromContext(ctx)
+}
+
+// Finish sets the span's end time.
+func (s *Span) Finish() {}
+
+type spanContextKey struct{}
@@ -0,0 +1,22 @@
+package suggestedfixes
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+)
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.RecordError(err)
+		return err // want "return can be reached without calling span.SetStatus"
+	}
+
+	span.SetStatus(otelcodes.Ok, "")
+	return nil
+}
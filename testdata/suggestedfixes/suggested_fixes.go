@@ -0,0 +1,96 @@
+package suggestedfixes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+)
+
+func _() {
+	ctx, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	print(ctx.Done(), span.IsRecording())
+} // want "return can be reached without calling span.End"
+
+func _() {
+	var ctx, span = otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	print(ctx.Done(), span.IsRecording())
+} // want "return can be reached without calling span.End"
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "foo") // want "span.End is not called on all paths, possible memory leak"
+	fmt.Print(span)
+} // want "return can be reached without calling span.End"
+
+func _() {
+	f := func() {
+		_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+		fmt.Print(span)
+	} // want "return can be reached without calling span.End"
+	f()
+}
+
+func _() {
+	switch {
+	case true:
+		_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+		fmt.Print(span)
+		return // want "return can be reached without calling span.End"
+	}
+}
+
+// No fix is offered if End is called on some paths, as a deferred End would end the span twice.
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.End is not called on all paths, possible memory leak"
+	defer func() {
+		if true {
+			span.End()
+		}
+	}()
+} // want "return can be reached without calling span.End"
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		span.RecordError(err)
+		return err // want "return can be reached without calling span.SetStatus"
+	}
+
+	return nil
+}
+
+func _() (string, error) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths" "span.RecordError is not called on all paths"
+	defer span.End()
+
+	if err := errors.New("foo"); err != nil {
+		return "", err // want "return can be reached without calling span.SetStatus" "return can be reached without calling span.RecordError"
+	}
+
+	return "", nil
+}
+
+func _() error {
+	_, span := trace.StartSpan(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	err := errors.New("foo")
+	return err // want "return can be reached without calling span.SetStatus"
+}
+
+// No fix is offered if the returned error is not a variable.
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar") // want "span.SetStatus is not called on all paths"
+	defer span.End()
+
+	if true {
+		span.RecordError(errors.New("foo"))
+		return errors.New("foo") // want "return can be reached without calling span.SetStatus"
+	}
+
+	return nil
+}
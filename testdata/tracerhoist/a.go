@@ -0,0 +1,22 @@
+package tracerhoist
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("a").Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("a").Start(ctx, "bar") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context, tracer string) {
+	_, span := otel.Tracer("a").Start(ctx, tracer) // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
@@ -0,0 +1,100 @@
+package tracerusage
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const importPath = "github.com/jjti/go-spancheck/testdata/tracerusage"
+
+var (
+	pkgTracer     = otel.Tracer(importPath)
+	emptyTracer   = otel.Tracer("")    // want "tracer name is empty"
+	otherTracer   = otel.Tracer("foo") // want "tracer name \"foo\" is not the package import path \"github.com/jjti/go-spancheck/testdata/tracerusage\""
+	versionTracer = otel.Tracer(importPath, trace.WithInstrumentationVersion("v1.0.0"))
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer(importPath).Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := otel.Tracer("").Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var" "tracer name is empty"
+	defer span.End()
+}
+
+func _(ctx context.Context, tp trace.TracerProvider) {
+	_, span := tp.Tracer(importPath).Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := otel.GetTracerProvider().Tracer(importPath).Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context, name string) {
+	_, span := otel.Tracer(name).Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	tracer := otel.Tracer(importPath, trace.WithInstrumentationVersion("v1.0.0")) // want "tracer is created on every call, create it once in a package-level var"
+	_, span := tracer.Start(ctx, "foo")
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	f := func() {
+		_, span := otel.Tracer(importPath).Start(ctx, "foo") // want "tracer is created on every call, create it once in a package-level var"
+		defer span.End()
+	}
+	f()
+}
+
+// correct
+
+func init() {
+	otel.Tracer(importPath)
+}
+
+func _(ctx context.Context) {
+	_, span := pkgTracer.Start(ctx, "foo")
+	defer span.End()
+}
+
+func _(ctx context.Context) {
+	_, span := versionTracer.Start(ctx, "foo")
+	defer span.End()
+}
+
+type service struct {
+	tracer trace.Tracer
+}
+
+func newService(tp trace.TracerProvider) *service {
+	return &service{tracer: tp.Tracer(importPath)}
+}
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	return tp.Tracer(importPath)
+}
+
+func (s *service) _(tp trace.TracerProvider) {
+	s.tracer = tp.Tracer(importPath)
+}
+
+func _(tp trace.TracerProvider) *service {
+	tracer := tp.Tracer(importPath)
+	return &service{tracer: tracer}
+}
+
+func (s *service) _(ctx context.Context) {
+	_, span := s.tracer.Start(ctx, "foo")
+	defer span.End()
+}
@@ -0,0 +1,127 @@
+package useafterend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// incorrect
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+	span.SetAttributes(attribute.String("foo", "bar")) // want "span.SetAttributes is called after span.End"
+}
+
+func _(fail bool) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	if fail {
+		span.End()
+	}
+
+	span.AddEvent("foo") // want "span.AddEvent is called after span.End"
+}
+
+func _() error {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+
+	err := errors.New("foo")
+	span.RecordError(err) // want "span.RecordError is called after span.End"
+	return err
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+	defer span.SetStatus(codes.Error, "foo") // want "span.SetStatus is called after span.End"
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	s := span
+	span.End()
+	s.AddEvent("foo") // want "span.AddEvent is called after span.End"
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	for i := 0; i < 3; i++ {
+		span.AddEvent("foo") // want "span.AddEvent is called after span.End"
+		span.End()
+	}
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "foo")
+	span.End()
+	span.Annotate(nil, "foo") // want "span.Annotate is called after span.End"
+}
+
+// correct
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.AddEvent("foo")
+	span.End()
+
+	fmt.Print(span.SpanContext().TraceID(), span.IsRecording())
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	defer span.End()
+
+	span.AddEvent("foo")
+}
+
+func _(fail bool) {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	if fail {
+		span.End()
+		return
+	}
+
+	span.AddEvent("foo")
+	span.End()
+}
+
+func _() {
+	for i := 0; i < 3; i++ {
+		_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+		span.AddEvent("foo")
+		span.End()
+	}
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+
+	_, span = otel.Tracer("foo").Start(context.Background(), "bar")
+	span.AddEvent("foo")
+	span.End()
+}
+
+func _() {
+	_, span := otel.Tracer("foo").Start(context.Background(), "bar")
+	span.End()
+
+	_, span = otel.Tracer("foo").Start(context.Background(), "bar")
+	defer func() { span.End() }()
+
+	span.AddEvent("foo")
+}
+
+func _() {
+	_, span := trace.StartSpan(context.Background(), "foo")
+	span.End()
+
+	fmt.Print(span.String(), span.IsRecordingEvents())
+}
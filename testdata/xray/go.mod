@@ -0,0 +1,7 @@
+module github.com/jjti/go-spancheck/testdata/xray
+
+go 1.20
+
+require github.com/aws/aws-xray-sdk-go v1.8.4
+
+replace github.com/aws/aws-xray-sdk-go => ../stubs/aws-xray-sdk-go
@@ -0,0 +1,92 @@
+package xray
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// incorrect
+
+func _(ctx context.Context) {
+	_, seg := xray.BeginSubsegment(ctx, "foo") // want "seg.Close is not called on all paths, possible memory leak"
+	seg.AddAnnotation("foo", "bar")
+} // want "return can be reached without calling seg.Close"
+
+func _(ctx context.Context) error {
+	ctx, seg := xray.BeginSegment(ctx, "foo") // want "seg.Close is not called on all paths, possible memory leak"
+	if err := task(ctx); err != nil {
+		return err // want "return can be reached without calling seg.Close"
+	}
+
+	seg.Close(nil)
+	return nil
+}
+
+func _(ctx context.Context) error {
+	_, seg := xray.BeginSubsegment(ctx, "foo")
+	defer seg.Close(nil) // want "seg.Close is called with nil before returning an error"
+
+	return errors.New("foo")
+}
+
+func _(ctx context.Context) error {
+	ctx, seg := xray.BeginSubsegment(ctx, "foo")
+	if err := task(ctx); err != nil {
+		seg.Close(nil) // want "seg.Close is called with nil before returning an error"
+		return err
+	}
+
+	seg.Close(nil)
+	return nil
+}
+
+func _(ctx context.Context) error {
+	return xray.Capture(ctx, "foo", func(ctx context.Context) error {
+		xray.GetSegment(ctx).Close(nil) // want "span from xray.GetSegment is ended, it is owned by the code that started it"
+		return task(ctx)
+	})
+}
+
+func _(ctx context.Context) (err error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "foo")
+	defer seg.Close(err) // want `seg.Close\(err\) is deferred with err evaluated at the defer statement; use defer func\(\) { seg.Close\(err\) }\(\)`
+
+	err = task(ctx)
+	return err
+}
+
+// correct
+
+func _(ctx context.Context) error {
+	ctx, seg := xray.BeginSubsegment(ctx, "foo")
+	err := task(ctx)
+	seg.Close(err)
+	return err
+}
+
+func _(ctx context.Context) (err error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "foo")
+	defer func() { seg.Close(err) }()
+
+	return task(ctx)
+}
+
+func _(ctx context.Context) {
+	ctx, seg := xray.BeginSubsegment(ctx, "foo")
+	defer seg.Close(nil)
+
+	_ = task(ctx)
+}
+
+func _(ctx context.Context) error {
+	return xray.Capture(ctx, "foo", func(ctx context.Context) error {
+		xray.GetSegment(ctx).AddAnnotation("foo", "bar")
+		return task(ctx)
+	})
+}
+
+func task(ctx context.Context) error {
+	return nil
+}
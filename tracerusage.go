@@ -0,0 +1,357 @@
+package spancheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	otelPath         = "go.opentelemetry.io/otel"
+	selNameTracer    = "Tracer"
+	tracerProvider   = "TracerProvider"
+	tracerVarName    = "tracer"
+	selNameStart     = "Start"
+	initFuncName     = "init"
+	tracerNameArgIdx = 0
+)
+
+// checkTracerUsage reports tracers created in function bodies to start spans, where they
+// are looked up on every call, and tracers with empty names. If requireImportPath is true, tracer names
+// must be the import path of the package.
+func checkTracerUsage(pass *analysis.Pass, inspect *inspector.Inspector, requireImportPath bool) {
+	var hoisted []*ast.CallExpr
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || !isTracerFunc(fn) || len(call.Args) <= tracerNameArgIdx {
+			return true
+		}
+
+		if inFuncBody(stack) && startsSpans(pass, stack) {
+			hoisted = append(hoisted, call)
+		}
+
+		name := pass.TypesInfo.Types[call.Args[tracerNameArgIdx]]
+		if name.Value == nil || name.Value.Kind() != constant.String {
+			return true
+		}
+
+		switch val := constant.StringVal(name.Value); {
+		case val == "":
+			pass.ReportRangef(call.Args[tracerNameArgIdx], "tracer name is empty")
+		case requireImportPath && val != pass.Pkg.Path():
+			pass.ReportRangef(call.Args[tracerNameArgIdx], "tracer name %q is not the package import path %q", val, pass.Pkg.Path())
+		}
+
+		return true
+	})
+
+	// The fixes of all calls with a tracer name share a var, so they are found together.
+	vars := getTracerVars(pass, hoisted)
+	for _, call := range hoisted {
+		pass.Report(analysis.Diagnostic{
+			Pos:            call.Pos(),
+			End:            call.End(),
+			Message:        "tracer is created on every call, create it once in a package-level var",
+			SuggestedFixes: getTracerFixes(pass, call, vars),
+		})
+	}
+}
+
+// isTracerFunc reports whether fn is otel.Tracer, or the Tracer method of a TracerProvider.
+func isTracerFunc(fn *types.Func) bool {
+	if fn.Name() != selNameTracer || fn.Pkg() == nil || !strings.HasPrefix(fn.Pkg().Path(), otelPath) {
+		return false
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Pkg().Path() == otelPath
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+
+	return ok && named.Obj().Name() == tracerProvider
+}
+
+// inFuncBody reports whether the top of the stack is in the body of a function, other
+// than a package initializer.
+func inFuncBody(stack []ast.Node) bool {
+	for _, n := range stack {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return n.Recv != nil || n.Name.Name != initFuncName
+		case *ast.FuncLit:
+			return true
+		}
+	}
+
+	return false
+}
+
+// startsSpans reports whether the tracer created by the call at the top of the stack
+// starts spans in the function: Start is called on it, or on the local var it is
+// assigned to. Tracers that are stored or returned, like those created from injected
+// TracerProviders in constructors, are created once and not reported.
+func startsSpans(pass *analysis.Pass, stack []ast.Node) bool {
+	if len(stack) < 3 {
+		return false
+	}
+	call := stack[len(stack)-1]
+
+	// otel.Tracer("app").Start(ctx, "span")
+	switch parent := stack[len(stack)-2].(type) {
+	case *ast.SelectorExpr:
+		caller, ok := stack[len(stack)-3].(*ast.CallExpr)
+		return ok && parent.X == call && parent.Sel.Name == selNameStart && caller.Fun == parent
+	case *ast.AssignStmt:
+		if len(parent.Lhs) != len(parent.Rhs) {
+			return false
+		}
+		for i, rhs := range parent.Rhs {
+			if rhs == call {
+				id, ok := parent.Lhs[i].(*ast.Ident)
+				return ok && onlyStartsSpans(pass, id, stack)
+			}
+		}
+	case *ast.ValueSpec:
+		if len(parent.Names) != len(parent.Values) {
+			return false
+		}
+		for i, val := range parent.Values {
+			if val == call {
+				return onlyStartsSpans(pass, parent.Names[i], stack)
+			}
+		}
+	}
+
+	return false
+}
+
+// onlyStartsSpans reports whether id is a local var whose tracer is only used to start
+// spans in the innermost function of the stack.
+func onlyStartsSpans(pass *analysis.Pass, id *ast.Ident, stack []ast.Node) bool {
+	v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+	if !ok || v.Parent() == pass.Pkg.Scope() {
+		return false
+	}
+
+	var body ast.Node
+	for _, n := range stack {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			body = n
+		}
+	}
+	if body == nil {
+		return false
+	}
+
+	// Uses of the var are tracer.Start calls, assignments to it, or others.
+	starts, others := 0, 0
+	skip := make(map[*ast.Ident]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if lhs, ok := lhs.(*ast.Ident); ok {
+					skip[lhs] = true
+				}
+			}
+		case *ast.CallExpr:
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == selNameStart {
+				if x, ok := sel.X.(*ast.Ident); ok && pass.TypesInfo.Uses[x] == v {
+					skip[x] = true
+					starts++
+				}
+			}
+		case *ast.Ident:
+			if !skip[n] && pass.TypesInfo.Uses[n] == v {
+				others++
+			}
+		}
+		return true
+	})
+
+	return starts > 0 && others == 0
+}
+
+// tracerVar is a package-level var that tracers created in function bodies are moved to.
+type tracerVar struct {
+	// name is the name of the var.
+	name string
+
+	// decl declares the var, and insert adds the declaration after the imports of the
+	// file of the first call moved to it.
+	decl   string
+	insert analysis.TextEdit
+}
+
+// getTracerVars returns the vars that the hoisted tracers are moved to, by tracer name.
+// Each tracer name gets one var with a name of its own: tracer, tracer2, and so on. No
+// var is declared for TracerProvider.Tracer, whose provider is usually local, or for
+// tracers with options or empty names.
+func getTracerVars(pass *analysis.Pass, calls []*ast.CallExpr) map[string]*tracerVar {
+	vars := make(map[string]*tracerVar)
+	for _, call := range calls {
+		name, ok := getTracerName(pass, typeutil.StaticCallee(pass.TypesInfo, call), call)
+		if !ok || vars[name] != nil {
+			continue
+		}
+
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		pos := getImportsEnd(getFile(pass, call.Pos()))
+		if !pos.IsValid() {
+			continue
+		}
+
+		// Names that are not declared in the package, like local constants, are inlined.
+		nameExpr := types.ExprString(call.Args[0])
+		if !isPackageLevel(pass, call.Args[0]) {
+			nameExpr = strconv.Quote(name)
+		}
+
+		varName := getFreeTracerVarName(pass, vars)
+		decl := fmt.Sprintf("var %s = %s(%s)", varName, types.ExprString(fun), nameExpr)
+		vars[name] = &tracerVar{
+			name: varName,
+			decl: decl,
+			insert: analysis.TextEdit{
+				Pos:     pos,
+				End:     pos,
+				NewText: []byte("\n\n" + decl),
+			},
+		}
+	}
+
+	return vars
+}
+
+// getTracerName returns the name of the tracer created by call, if its var can be
+// declared at package level.
+func getTracerName(pass *analysis.Pass, fn *types.Func, call *ast.CallExpr) (string, bool) {
+	if fn == nil || fn.Type().(*types.Signature).Recv() != nil || len(call.Args) != 1 {
+		return "", false
+	}
+
+	// Empty names have to be replaced, not hoisted.
+	name := pass.TypesInfo.Types[call.Args[0]].Value
+	if name == nil || name.Kind() != constant.String || constant.StringVal(name) == "" {
+		return "", false
+	}
+
+	return constant.StringVal(name), true
+}
+
+// getFreeTracerVarName returns the first of tracer, tracer2, and so on, that is neither
+// declared in the package nor taken by one of vars.
+func getFreeTracerVarName(pass *analysis.Pass, vars map[string]*tracerVar) string {
+	taken := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		taken[v.name] = true
+	}
+
+	for i := 1; ; i++ {
+		name := tracerVarName
+		if i > 1 {
+			name += strconv.Itoa(i)
+		}
+
+		if !taken[name] && pass.Pkg.Scope().Lookup(name) == nil {
+			return name
+		}
+	}
+}
+
+// getImportsEnd returns the end of the last import declaration of file, or an invalid
+// position if it has none.
+func getImportsEnd(file *ast.File) token.Pos {
+	var pos token.Pos
+	if file == nil {
+		return pos
+	}
+
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			pos = gen.End()
+		}
+	}
+
+	return pos
+}
+
+// getTracerFixes returns a fix that moves the tracer created by call to its package-level
+// var. Every fix for a tracer name declares the same var, so that applying them all
+// declares it once. No fix is offered if the var's name is taken where call is.
+func getTracerFixes(pass *analysis.Pass, call *ast.CallExpr, vars map[string]*tracerVar) []analysis.SuggestedFix {
+	name, ok := getTracerName(pass, typeutil.StaticCallee(pass.TypesInfo, call), call)
+	if !ok || vars[name] == nil {
+		return nil
+	}
+	v := vars[name]
+
+	scope := pass.Pkg.Scope().Innermost(call.Pos())
+	if scope == nil {
+		return nil
+	}
+	if _, obj := scope.LookupParent(v.name, call.Pos()); obj != nil {
+		return nil
+	}
+
+	return []analysis.SuggestedFix{{
+		Message: "Move to " + v.decl,
+		TextEdits: []analysis.TextEdit{
+			v.insert,
+			{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				NewText: []byte(v.name),
+			},
+		},
+	}}
+}
+
+// isPackageLevel reports whether expr only refers to objects declared at package level,
+// or in other packages.
+func isPackageLevel(pass *analysis.Pass, expr ast.Expr) bool {
+	ok := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		id, isIdent := n.(*ast.Ident)
+		if !isIdent {
+			return true
+		}
+
+		obj := pass.TypesInfo.Uses[id]
+		if obj != nil && obj.Pkg() == pass.Pkg && obj.Parent() != pass.Pkg.Scope() {
+			ok = false
+		}
+
+		return ok
+	})
+
+	return ok
+}
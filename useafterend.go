@@ -0,0 +1,51 @@
+package spancheck
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// checkUseAfterEnd reports calls on the span that can be made after End was called on it.
+// Data added to a span after it has ended is dropped.
+func checkUseAfterEnd(pass *analysis.Pass, info *ssaInfo, fn *ssa.Function, sv spanVar) {
+	s := &callSearch{
+		pass:    pass,
+		info:    info,
+		aliases: newValueAliases(fn, sv.val),
+		selName: sv.lib.EndMethod,
+	}
+
+	calls := info.getCallExprs(fn)
+	reported := make(map[ssa.CallInstruction]bool)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			// Deferred calls to End run last, so only calls made in place count.
+			end, ok := instr.(*ssa.Call)
+			if !ok || !s.callsMethod(end.Common(), s.selName) {
+				continue
+			}
+
+			use := s.findCall(end, s.isStartOrReassign(sv.start), func(call ssa.CallInstruction) bool {
+				method := s.getSpanMethod(call.Common())
+				if method == "" || method == s.selName {
+					return false
+				}
+
+				return !sv.lib.isReadOnlyMethod(method)
+			})
+			if use == nil || reported[use] {
+				continue
+			}
+			reported[use] = true
+
+			msg := fmt.Sprintf("%s.%s is called after %s.%s", sv.vr.Name(), s.getSpanMethod(use.Common()), sv.vr.Name(), s.selName)
+			if call, ok := calls[use.Common().Pos()]; ok {
+				pass.ReportRangef(call, "%s", msg)
+			} else {
+				pass.Reportf(use.Pos(), "%s", msg)
+			}
+		}
+	}
+}